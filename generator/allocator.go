@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MachineAllocator hands out node IDs from the sub-range [min, max] within this process, e.g. one per tenant,
+// a lighter alternative to NewWithRedisMachine when all generators of a fleet live in one process
+type MachineAllocator struct {
+	mutex   sync.Mutex
+	min     int64          // 可分配的最小节点ID
+	max     int64          // 可分配的最大节点ID
+	nodeIDs map[int64]bool // 已分配的节点ID
+}
+
+// NewMachineAllocator constructs an allocator of the node IDs [min, max], which must lie within the default layout
+func NewMachineAllocator(min, max int64) (*MachineAllocator, error) {
+	if min < 0 || max > maxNodeID || min > max {
+		return nil, fmt.Errorf("%w: the range [%d, %d] is not within [0, %d]", ErrNodeIDOverflow, min, max, maxNodeID)
+	}
+	return &MachineAllocator{min: min, max: max, nodeIDs: map[int64]bool{}}, nil
+}
+
+// Allocate returns the lowest node ID of the range that is not allocated, or ErrExhausted if all of them are
+func (a *MachineAllocator) Allocate() (int64, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for nodeID := a.min; nodeID <= a.max; nodeID++ {
+		if !a.nodeIDs[nodeID] {
+			a.nodeIDs[nodeID] = true
+			return nodeID, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: all %d node IDs of [%d, %d] are allocated", ErrExhausted, a.max-a.min+1, a.min, a.max)
+}
+
+// Release returns nodeID to the allocator, releasing a node ID that is not allocated changes nothing
+func (a *MachineAllocator) Release(nodeID int64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.nodeIDs, nodeID)
+}
+
+// New constructs a clock-based generator with an allocated node ID, which Close releases again.
+// The node ID and the clock mode override opts.
+func (a *MachineAllocator) New(opts ...Option) (*Butterfly, error) {
+	nodeID, err := a.Allocate()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := New(append(opts, WithClockMode(), WithNodeID(nodeID))...)
+	if err != nil {
+		a.Release(nodeID)
+		return nil, err
+	}
+	release := b.release
+	b.release = func() error {
+		defer a.Release(nodeID)
+		if release != nil {
+			return release()
+		}
+		return nil
+	}
+	return b, nil
+}
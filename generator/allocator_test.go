@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMachineAllocator(t *testing.T) {
+	tenantA, err := NewMachineAllocator(0, 2)
+	if err != nil {
+		t.Fatalf("failed to construct the allocator: %v", err)
+	}
+	tenantB, err := NewMachineAllocator(1000, 1001)
+	if err != nil {
+		t.Fatalf("failed to construct the allocator: %v", err)
+	}
+
+	// 测试节点ID是否从各自的范围内分配且不重复
+	for i := int64(0); i < 3; i++ {
+		if nodeID, err := tenantA.Allocate(); err != nil || nodeID != i {
+			t.Errorf("Unexpected node ID: %d (%v), expected %d", nodeID, err, i)
+		}
+	}
+	if _, err := tenantA.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrExhausted)
+	}
+	tenantA.Release(1)
+	if nodeID, err := tenantA.Allocate(); err != nil || nodeID != 1 {
+		t.Errorf("Unexpected node ID: %d (%v), expected %d", nodeID, err, 1)
+	}
+
+	// 测试生成器关闭后是否归还节点ID
+	b, err := tenantB.New(WithNodeID(5))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if nodeID := Decompose(id).NodeID; nodeID != 1000 {
+		t.Errorf("Unexpected node ID: %d, expected %d", nodeID, 1000)
+	}
+	if nodeID, err := tenantB.Allocate(); err != nil || nodeID != 1001 {
+		t.Errorf("Unexpected node ID: %d (%v), expected %d", nodeID, err, 1001)
+	}
+	if _, err := tenantB.New(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrExhausted)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("failed to close the generator: %v", err)
+	}
+	if nodeID, err := tenantB.Allocate(); err != nil || nodeID != 1000 {
+		t.Errorf("Unexpected node ID: %d (%v), expected %d", nodeID, err, 1000)
+	}
+
+	// 测试构造失败时是否归还节点ID，以及非法的范围
+	tenantB.Release(1000)
+	if _, err := tenantB.New(WithStartTimestamp(-1)); err == nil {
+		t.Error("invalid options expect an error, but got nil")
+	}
+	if nodeID, err := tenantB.Allocate(); err != nil || nodeID != 1000 {
+		t.Errorf("Unexpected node ID: %d (%v), expected %d", nodeID, err, 1000)
+	}
+	for _, r := range [][2]int64{{-1, 1}, {0, maxNodeID + 1}, {2, 1}} {
+		if _, err := NewMachineAllocator(r[0], r[1]); err == nil {
+			t.Errorf("range %v expects an error, but got nil", r)
+		}
+	}
+}
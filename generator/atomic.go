@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// AtomicButterfly is a lock-free variant of Butterfly with the default layout.
+// Its state word is the last generated id itself: as the fields are carried from low sequence
+// over node ID and high sequence up to the timestamp, advancing the state by one is exactly
+// what Butterfly.Generate does, so a compare-and-swap loop replaces the mutex.
+type AtomicButterfly struct {
+	noCopy noCopy // 禁止复制
+	state  uint64 // 状态字，即上一个生成的ID
+}
+
+func NewAtomicButterfly(initTimestamp int64) *AtomicButterfly {
+	return &AtomicButterfly{state: uint64(initTimestamp&maxTimestamp) << timeShift}
+}
+
+func (b *AtomicButterfly) Generate() (int64, error) {
+	last, ok := b.advance(1)
+	if !ok {
+		return 0, fmt.Errorf("%w: the timestamp exceeds the max timestamp %d", ErrTimestampOverflow, int64(maxTimestamp))
+	}
+	return last, nil
+}
+
+// GenerateInBatches reserves count consecutive ids with a single compare-and-swap.
+// If fewer than count ids remain it returns an error without generating any of them.
+func (b *AtomicButterfly) GenerateInBatches(count int) ([]int64, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("invalid count %d: count must not be negative", count)
+	}
+
+	last, ok := b.advance(uint64(count))
+	if !ok {
+		return nil, fmt.Errorf("%w: %d ids are requested but fewer remain below the max timestamp %d", ErrTimestampOverflow, count, int64(maxTimestamp))
+	}
+	idList := make([]int64, 0, count)
+	for i := count - 1; i >= 0; i-- {
+		idList = append(idList, last-int64(i))
+	}
+	return idList, nil
+}
+
+// maxState is the largest id, after which the timestamp would be carried into the sign bit
+const maxState = 1<<63 - 1
+
+// advance moves the state forward by n and returns the new state, it fails if that would pass maxState
+func (b *AtomicButterfly) advance(n uint64) (int64, bool) {
+	for {
+		old := atomic.LoadUint64(&b.state)
+		if n > maxState-old {
+			return 0, false
+		}
+		next := old + n
+		if atomic.CompareAndSwapUint64(&b.state, old, next) {
+			return int64(next), true
+		}
+	}
+}
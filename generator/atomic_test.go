@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAtomicButterfly_Generate(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+
+	// 测试与Butterfly生成的ID是否一致
+	b := NewButterfly(initTimestamp)
+	a := NewAtomicButterfly(initTimestamp)
+	for i := 0; i < 100000; i++ {
+		id, err := a.Generate()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if expected := mustGenerate(t, b); id != expected {
+			t.Fatalf("Unexpected id: %d, expected %d on %d times loop", id, expected, i)
+		}
+	}
+	expectedList, _ := b.GenerateInBatches(1000)
+	idList, err := a.GenerateInBatches(1000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	for i := range expectedList {
+		if idList[i] != expectedList[i] {
+			t.Fatalf("Unexpected id: %d, expected %d on %d index", idList[i], expectedList[i], i)
+		}
+	}
+}
+
+func TestAtomicButterfly_Concurrent(t *testing.T) {
+	const goroutines = 16
+	const perGoroutine = 125000
+	a := NewAtomicButterfly(time.Now().UnixMilli())
+
+	// 测试并发生成的ID是否无重复
+	results := make([][]int64, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			idList := make([]int64, 0, perGoroutine)
+			for i := 0; i < perGoroutine; i++ {
+				id, err := a.Generate()
+				if err != nil {
+					t.Errorf("failed to generate id: %v", err)
+					return
+				}
+				idList = append(idList, id)
+			}
+			results[g] = idList
+		}(g)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]struct{}, goroutines*perGoroutine)
+	for _, idList := range results {
+		for _, id := range idList {
+			if _, ok := seen[id]; ok {
+				t.Fatalf("duplicate id: %d", id)
+			}
+			seen[id] = struct{}{}
+		}
+	}
+}
+
+func TestAtomicButterfly_Exhausted(t *testing.T) {
+	a := NewAtomicButterfly(maxTimestamp)
+	a.state = maxState - 2
+
+	// 测试剩余数量不足时是否不生成任何ID
+	if _, err := a.GenerateInBatches(3); err == nil {
+		t.Error("exhausted generator expects an error, but got nil")
+	}
+	idList, err := a.GenerateInBatches(2)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if len(idList) != 2 || idList[0] != maxState-1 || idList[1] != maxState {
+		t.Errorf("Unexpected ids: %v, expected [%d %d]", idList, int64(maxState-1), int64(maxState))
+	}
+	if _, err := a.Generate(); err == nil {
+		t.Error("exhausted generator expects an error, but got nil")
+	}
+}
@@ -0,0 +1,58 @@
+package generator
+
+import "fmt"
+
+// AuditReport lists the anomalies AuditBatch found in a batch of ids
+type AuditReport struct {
+	Count      int          // 审计的ID数
+	Duplicates []AuditEntry // 重复出现的ID，Other为其首次出现的位置
+	Invalid    []AuditEntry // 字段越界的ID，Err为越界的原因
+	Unordered  []AuditEntry // 不大于前一个ID的ID，Other为前一个ID的位置
+}
+
+// AuditEntry is an id flagged by AuditBatch
+type AuditEntry struct {
+	Index int   // ID在批次中的位置
+	ID    int64 // ID的值
+	Other int   // 相关的另一个位置，无则为-1
+	Err   error // 字段越界时的错误
+}
+
+// AuditBatch scans ids of the default layout in one pass for duplicates, fields out of range and ids that are
+// not greater than their predecessor, as a batch of one generator in creation order never has any of them.
+// It returns the full report, and an error summing it up if anything was found, wrapping ErrDuplicate for duplicates.
+func AuditBatch(ids []int64) (AuditReport, error) {
+	return defaultLayout.auditBatch(ids)
+}
+
+// AuditBatch is like the package level AuditBatch but honors the layout of the generator
+func (b *Butterfly) AuditBatch(ids []int64) (AuditReport, error) {
+	return b.layout.auditBatch(ids)
+}
+
+func (l layout) auditBatch(ids []int64) (AuditReport, error) {
+	report := AuditReport{Count: len(ids)}
+	seen := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		if first, ok := seen[id]; ok {
+			report.Duplicates = append(report.Duplicates, AuditEntry{Index: i, ID: id, Other: first})
+		} else {
+			seen[id] = i
+		}
+		if err := l.validate(id); err != nil {
+			report.Invalid = append(report.Invalid, AuditEntry{Index: i, ID: id, Other: -1, Err: err})
+		}
+		if i > 0 && id <= ids[i-1] {
+			report.Unordered = append(report.Unordered, AuditEntry{Index: i, ID: id, Other: i - 1})
+		}
+	}
+
+	summary := fmt.Sprintf("%d duplicates, %d invalid ids and %d unordered ids among %d ids", len(report.Duplicates), len(report.Invalid), len(report.Unordered), len(ids))
+	switch {
+	case len(report.Duplicates) > 0:
+		return report, fmt.Errorf("%w: %s", ErrDuplicate, summary)
+	case len(report.Invalid) > 0 || len(report.Unordered) > 0:
+		return report, fmt.Errorf("the audit found %s", summary)
+	}
+	return report, nil
+}
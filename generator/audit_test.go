@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuditBatch(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	ids, err := b.GenerateInBatches(100000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+
+	// 测试正常批次是否没有任何问题
+	report, err := AuditBatch(ids)
+	if err != nil || report.Count != len(ids) || len(report.Duplicates)+len(report.Invalid)+len(report.Unordered) != 0 {
+		t.Errorf("Unexpected report: %+v (%v), expected nothing found", report, err)
+	}
+
+	// 测试重复、越界与乱序的ID是否被标出位置
+	ids = append(ids[:10], ids[3], -1, ids[20], ids[15])
+	report, err = AuditBatch(ids)
+	if !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrDuplicate)
+	}
+	if len(report.Duplicates) != 1 || report.Duplicates[0] != (AuditEntry{Index: 10, ID: ids[3], Other: 3}) {
+		t.Errorf("Unexpected duplicates: %+v", report.Duplicates)
+	}
+	var fieldErr *FieldError
+	if len(report.Invalid) != 1 || report.Invalid[0].Index != 11 || !errors.As(report.Invalid[0].Err, &fieldErr) {
+		t.Errorf("Unexpected invalid ids: %+v", report.Invalid)
+	}
+	expected := []AuditEntry{{Index: 10, ID: ids[10], Other: 9}, {Index: 11, ID: -1, Other: 10}, {Index: 13, ID: ids[13], Other: 12}}
+	if len(report.Unordered) != len(expected) {
+		t.Fatalf("Unexpected unordered ids: %+v, expected %+v", report.Unordered, expected)
+	}
+	for i := range expected {
+		if report.Unordered[i] != expected[i] {
+			t.Errorf("Unexpected unordered id: %+v, expected %+v", report.Unordered[i], expected[i])
+		}
+	}
+
+	if _, err := AuditBatch([]int64{2, 1}); err == nil || errors.Is(err, ErrDuplicate) {
+		t.Errorf("Unexpected error: %v, expected an ordering error", err)
+	}
+}
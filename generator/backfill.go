@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+)
+
+// GenerateAt generates an id for a timestamp earlier than the current one, e.g. to backfill historical records,
+// without disturbing the counter of Generate. Each timestamp has its own sequences tracked in memory, so repeated calls
+// never collide with each other, but they may collide with ids Generate emitted at that timestamp before:
+// backfill with a generator of a dedicated node ID.
+func (b *Butterfly) GenerateAt(timestamp int64) (int64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	err := b.throttle(context.Background(), 1, func() error {
+		if timestamp < b.epoch {
+			return fmt.Errorf("the timestamp %d is earlier than the epoch %d", timestamp, b.epoch)
+		}
+		if timestamp-b.epoch > b.layout.maxTimestamp {
+			return fmt.Errorf("%w: the timestamp %d exceeds the max timestamp %d", ErrTimestampOverflow, timestamp-b.epoch, b.layout.maxTimestamp)
+		}
+		if timestamp >= b.timestamp {
+			return fmt.Errorf("the timestamp %d is not earlier than the current timestamp %d", timestamp, b.timestamp)
+		}
+		if used := b.backfill[timestamp]; used >= b.layout.maxIDsPerTick() {
+			return fmt.Errorf("%w: all %d ids of the timestamp %d are generated", ErrExhausted, used, timestamp)
+		}
+		return nil
+	})
+	if err != nil {
+		return b.observe(0, err)
+	}
+
+	used := b.backfill[timestamp]
+	if b.backfill == nil {
+		b.backfill = make(map[int64]int64)
+	}
+	b.backfill[timestamp] = used + 1
+
+	lowSequences := b.layout.maxLowSequence + 1
+	id := ((timestamp - b.epoch) << b.layout.timeShift) |
+		((used / lowSequences) << b.layout.highSequenceShift) |
+		(b.nodeID << b.layout.nodeIDShift) |
+		((used % lowSequences) << b.layout.lowSequenceShift) |
+		b.layout.marker
+	return b.observe(id, nil)
+}
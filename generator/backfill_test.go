@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestButterfly_GenerateAt(t *testing.T) {
+	b, err := New(WithStartTimestamp(2000), WithNodeID(9), WithEpoch(100))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	mustGenerate(t, b)
+	last := b.compose()
+
+	// 测试同一时间戳的回填ID是否唯一且不影响主计数器
+	seen := make(map[int64]bool)
+	for i := int64(0); i < MaxIDsPerMillisecond(); i++ {
+		id, err := b.GenerateAt(1000)
+		if err != nil {
+			t.Fatalf("failed to generate id on %d times loop: %v", i, err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d on %d times loop", id, i)
+		}
+		seen[id] = true
+		if c := b.Decompose(id); c.Timestamp != 1000 || c.NodeID != 9 {
+			t.Errorf("Unexpected components: %+v, expected timestamp 1000 and node ID 9", c)
+		}
+	}
+	if b.compose() != last {
+		t.Errorf("Unexpected state: %d, expected %d", b.compose(), last)
+	}
+	if _, err := b.GenerateAt(1000); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrExhausted)
+	}
+	if _, err := b.GenerateAt(1001); err != nil {
+		t.Errorf("another timestamp expects no error, but got %v", err)
+	}
+
+	// 测试非法的时间戳是否被拒绝
+	for _, timestamp := range []int64{99, 2000, 3000} {
+		if _, err := b.GenerateAt(timestamp); err == nil {
+			t.Errorf("timestamp %d expects an error, but got nil", timestamp)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// EncodeBase62 renders id as a base62 string without leading zeros, negative ids are encoded by their two's complement
+func EncodeBase62(id int64) string {
+	value := uint64(id)
+	if value == 0 {
+		return base62Alphabet[:1]
+	}
+
+	var buf [11]byte // 62^11 > 2^64
+	i := len(buf)
+	for value > 0 {
+		i--
+		buf[i] = base62Alphabet[value%62]
+		value /= 62
+	}
+	return string(buf[i:])
+}
+
+// DecodeBase62 is the inverse of EncodeBase62, it rejects leading zeros so that every id has exactly one encoding
+func DecodeBase62(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("failed to decode base62: empty string")
+	}
+	if len(s) > 1 && s[0] == base62Alphabet[0] {
+		return 0, fmt.Errorf("failed to decode base62 %q: leading zeros are not allowed", s)
+	}
+
+	var value uint64
+	for i := 0; i < len(s); i++ {
+		digit := base62Digit(s[i])
+		if digit < 0 {
+			return 0, fmt.Errorf("failed to decode base62 %q: invalid character %q at %d", s, s[i], i)
+		}
+		if value > (math.MaxUint64-uint64(digit))/62 {
+			return 0, fmt.Errorf("failed to decode base62 %q: value overflows 64 bits", s)
+		}
+		value = value*62 + uint64(digit)
+	}
+	return int64(value), nil
+}
+
+func base62Digit(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 36
+	default:
+		return -1
+	}
+}
+
+// GenerateString generates an id and encodes it as base62
+func (b *Butterfly) GenerateString() (string, error) {
+	id, err := b.Generate()
+	if err != nil {
+		return "", err
+	}
+	return EncodeBase62(id), nil
+}
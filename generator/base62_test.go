@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBase62(t *testing.T) {
+	// 测试编码后解码是否得到原ID
+	b := NewButterfly(time.Now().UnixMilli())
+	cases := []int64{0, 1, 61, 62, math.MaxInt64, -1, math.MinInt64}
+	for i := 0; i < 1000; i++ {
+		cases = append(cases, mustGenerate(t, b))
+	}
+	for _, id := range cases {
+		s := EncodeBase62(id)
+		decoded, err := DecodeBase62(s)
+		if err != nil {
+			t.Fatalf("failed to decode %q: %v", s, err)
+		}
+		if decoded != id {
+			t.Errorf("Unexpected decoded id: %d, expected %d", decoded, id)
+		}
+	}
+
+	if s := EncodeBase62(62); s != "10" {
+		t.Errorf("Unexpected encoding: %q, expected %q", s, "10")
+	}
+
+	// 测试非法输入是否返回错误
+	for _, s := range []string{"", "00", "01", "a-b", "zzzzzzzzzzzz"} {
+		if _, err := DecodeBase62(s); err == nil {
+			t.Errorf("decoding %q expects an error, but got nil", s)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Compare a change against its base with benchstat instead of fixed numbers, which go stale:
+//
+//	go test -run '^$' -bench . -count 10 ./generator > new.txt
+//	git stash && go test -run '^$' -bench . -count 10 ./generator > old.txt && git stash pop
+//	benchstat old.txt new.txt
+
+func BenchmarkButterfly_Generate(b *testing.B) {
+	g := NewButterfly(time.Now().UnixMilli())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkButterfly_GenerateParallel measures the mutex contention path, compare it with BenchmarkAtomicButterfly_GenerateParallel
+func BenchmarkButterfly_GenerateParallel(b *testing.B) {
+	g := NewButterfly(time.Now().UnixMilli())
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := g.Generate(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkAtomicButterfly_GenerateParallel(b *testing.B) {
+	g := NewAtomicButterfly(time.Now().UnixMilli())
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := g.Generate(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkButterfly_GenerateWithClockParallel measures one clock-based generator, compare it with BenchmarkPool_GenerateParallel
+func BenchmarkButterfly_GenerateWithClockParallel(b *testing.B) {
+	g, err := NewClockBased(0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := g.GenerateWithClock(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkButterfly_GenerateWithCachedClock(b *testing.B) {
+	g, err := New(WithClockMode(), WithCachedClock())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.GenerateWithClock(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPool_GenerateParallel(b *testing.B) {
+	g, err := NewShardedPool(runtime.GOMAXPROCS(0), 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := g.Generate(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkButterfly_GenerateInBatches(b *testing.B) {
+	for _, count := range []int{10, 1000, 100000} {
+		b.Run(fmt.Sprintf("count=%d", count), func(b *testing.B) {
+			g := NewButterfly(time.Now().UnixMilli())
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := g.GenerateInBatches(count); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseID(b *testing.B) {
+	id := mustGenerate(b, NewButterfly(time.Now().UnixMilli()))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, _, err := ParseID(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
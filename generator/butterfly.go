@@ -1,9 +1,18 @@
 package generator
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"sync"
+	"time"
 )
 
+// The default layout of an id from the high bits to the low bits: 41 bits of timestamp, 8 bits of high sequence,
+// 13 bits of node ID and 1 bit of low sequence. Every field ranges over its full bit mask, so the low sequence
+// is only 0 or 1 and up to (maxHighSequence+1)*(maxLowSequence+1) ids fit into one millisecond of a fixed node.
+// Use Config to widen the low sequence, the shifts of the other fields are recomputed accordingly.
+// The fields take exactly 63 bits, so even the max timestamp packs below the sign bit and ids are never negative.
 const (
 	timestampBits     = 41
 	maxTimestamp      = -1 ^ (-1 << timestampBits)
@@ -14,50 +23,394 @@ const (
 	maxHighSequence   = -1 ^ (-1 << highSequenceBits)                 // 高序列号最大值
 	maxLowSequence    = -1 ^ (-1 << lowSequenceBits)                  // 低序列号最大值
 	timeShift         = highSequenceBits + nodeBits + lowSequenceBits // 时间戳位移量
-	highSequenceShift = nodeBits + lowSequenceBits                    // 低序列号位移量
+	highSequenceShift = nodeBits + lowSequenceBits                    // 高序列号位移量
 	nodeIDShift       = lowSequenceBits                               // 节点ID位移量
 )
 
+// Butterfly generates ids under a mutex. It must not be copied after first use, which go vet reports
+// through the mutex: a copy would emit the same ids as the original, use Clone or CloneWithNodeID instead.
 type Butterfly struct {
-	timestamp    int64      // 时间戳
-	highSequence int64      // 高序列号
-	lowSequence  int64      // 低序列号
-	nodeID       int64      // 节点ID
-	mutex        sync.Mutex // 互斥锁
+	timestamp      int64                // 时间戳
+	highSequence   int64                // 高序列号
+	lowSequence    int64                // 低序列号
+	nodeID         int64                // 节点ID
+	mutex          sync.Mutex           // 互斥锁
+	clock          func() int64         // 时钟函数，返回毫秒时间戳
+	milliClock     bool                 // 时钟是否来自只返回毫秒的Clock
+	lastTimestamp  int64                // 上次读取到的时钟时间戳
+	layout         layout               // 位布局
+	config         Config               // 构造位布局的配置，零值表示默认配置
+	epoch          int64                // 纪元，打包前从时间戳中减去的毫秒数
+	epochTime      time.Time            // 以时间表示的纪元，构造时换算为epoch
+	release        func() error         // 归还节点ID租约
+	observer       Observer             // 生成事件观察者
+	logger         Logger               // 诊断日志
+	exclusive      bool                 // 是否在进程内独占节点ID
+	clockBased     bool                 // 是否为时钟模式
+	randomStart    bool                 // 是否随机化起始序列号
+	randomTick     bool                 // 时钟模式下第一个新时间戳是否随机化序列号
+	overflowPolicy OverflowPolicy       // 序列号用尽时的策略
+	stats          Stats                // 生成计数
+	recent         *recentIDs           // 最近生成的ID，用于调试时检查重复
+	domainBits     int                  // 节点ID中分给域的低位数
+	domains        map[int64]*Butterfly // 各个非0域的生成器
+	limiter        *rateLimiter         // 限制生成速率的令牌桶
+	cachedClock    bool                 // 序列号用尽前是否沿用上次读取的时钟
+	closed         bool                 // 是否已关闭
+	backfill       map[int64]int64      // 各回填时间戳已生成的ID数
+	// RollbackThreshold is the max clock rollback in the timestamp unit, milliseconds by default, that GenerateWithClock waits out
+	RollbackThreshold int64
+	// MaxSkew is how far the timestamp of a clock-based generator may run ahead of its clock before Healthy fails, zero disables the check
+	MaxSkew time.Duration
 }
 
-func (b *Butterfly) Generate() int64 {
+// Generate returns an id strictly greater than the previous one of this instance. As the id is composed
+// before the mutex is released, a call that returns before another starts always gets the smaller id.
+// Once the timestamp would be carried beyond the max timestamp it returns ErrTimestampOverflow instead of wrapping around.
+// On a clock-based generator it is GenerateWithClock, so the node ID never changes.
+func (b *Butterfly) Generate() (int64, error) {
+	return b.generate(context.Background())
+}
+
+// generate is Generate, but waiting for rate limit tokens stops once ctx is done
+func (b *Butterfly) generate(ctx context.Context) (int64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err := b.throttle(ctx, 1, nil); err != nil {
+		return b.observe(0, err)
+	}
+	return b.observe(b.advance())
+}
+
+// GenerateUnique is like Generate but skips ids for which seen returns true, e.g. ids issued before a crash
+// that the restored state may not cover. It fails once the generator is exhausted.
+// seen runs while the generator is locked, so it must not call back into it.
+func (b *Butterfly) GenerateUnique(seen func(id int64) bool) (int64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err := b.throttle(context.Background(), 1, nil); err != nil {
+		return b.observe(0, err)
+	}
+
+	for {
+		id, err := b.advance()
+		if err != nil || !seen(id) {
+			return b.observe(id, err)
+		}
+	}
+}
+
+// GenerateTimestamped is like Generate but also returns the creation time packed into the id,
+// taken from the fields it just packed instead of parsing the id again
+func (b *Butterfly) GenerateTimestamped() (id int64, t time.Time, err error) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
+	if err := b.throttle(context.Background(), 1, nil); err != nil {
+		_, err = b.observe(0, err)
+		return 0, time.Time{}, err
+	}
+
+	id, err = b.observe(b.advance())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return id, b.layout.toTime(b.timestamp), nil
+}
+
+// advance moves to the next id in the mode of the generator: a clock-based generator follows the clock and keeps
+// its node ID, a logical one counts. The caller must hold the mutex.
+func (b *Butterfly) advance() (int64, error) {
+	if b.clockBased {
+		return b.nextWithClock()
+	}
+	return b.next()
+}
 
-	b.lowSequence = (b.lowSequence + 1) & maxLowSequence
-	if b.lowSequence == 0 {
-		b.nodeID = (b.nodeID + 1) & maxNodeID
-		if b.nodeID == 0 {
-			b.highSequence = (b.highSequence + 1) & maxHighSequence
-			if b.highSequence == 0 {
-				b.timestamp++
-			}
+// next advances the fields to the next id, the caller must hold the mutex.
+// The fields carry like one counter in the order they are packed: low sequence, then node ID, then high sequence,
+// then timestamp, or with SequenceAboveNode the node ID before the low sequence.
+func (b *Butterfly) next() (int64, error) {
+	if b.layout.sequenceAboveNode() && (b.nodeID < b.layout.maxNodeID || b.lowSequence < b.layout.maxLowSequence) {
+		if b.nodeID < b.layout.maxNodeID {
+			b.nodeID++
+		} else {
+			b.nodeID = 0
+			b.lowSequence++
 		}
+		return b.compose(), nil
 	}
 
-	id := ((b.timestamp & maxTimestamp) << timeShift) |
-		((b.highSequence & maxHighSequence) << highSequenceShift) |
-		((b.nodeID & maxNodeID) << nodeIDShift) |
-		(b.lowSequence & maxLowSequence)
+	switch {
+	case b.lowSequence < b.layout.maxLowSequence:
+		b.lowSequence++
+	case b.nodeID < b.layout.maxNodeID:
+		b.lowSequence = 0
+		b.nodeID++
+	case b.highSequence < b.layout.maxHighSequence:
+		b.lowSequence = 0
+		b.nodeID = 0
+		b.highSequence++
+	default:
+		if b.timestamp-b.epoch >= b.layout.maxTimestamp {
+			return 0, fmt.Errorf("%w: the timestamp %d is the max timestamp and cannot be carried", ErrTimestampOverflow, b.timestamp-b.epoch)
+		}
+		b.observeOverflow()
+		b.lowSequence = 0
+		b.nodeID = 0
+		b.highSequence = 0
+		b.timestamp++
+	}
+	return b.compose(), nil
+}
+
+// compose packs the current fields into an id, the caller must hold the mutex
+func (b *Butterfly) compose() int64 {
+	id := (((b.timestamp - b.epoch) & b.layout.maxTimestamp) << b.layout.timeShift) |
+		((b.highSequence & b.layout.maxHighSequence) << b.layout.highSequenceShift) |
+		((b.nodeID & b.layout.maxNodeID) << b.layout.nodeIDShift) |
+		((b.lowSequence & b.layout.maxLowSequence) << b.layout.lowSequenceShift) |
+		b.layout.marker
 	return id
 }
 
-func (b *Butterfly) GenerateInBatches(count int) []int64 {
-	var idList []int64
+// Close stops the background work of the generator, flushes its persisted state and returns its node ID lease, if any.
+// Later generations return ErrClosed, and closing again changes nothing and returns nil.
+func (b *Butterfly) Close() error {
+	b.mutex.Lock()
+	b.closed = true
+	for _, child := range b.domains {
+		child.mutex.Lock()
+		child.closed = true
+		child.mutex.Unlock()
+	}
+	release := b.release
+	b.release = nil
+	b.mutex.Unlock()
+
+	if release == nil {
+		return nil
+	}
+	return release()
+}
+
+// Timestamp returns a snapshot of the timestamp of the last generated id
+func (b *Butterfly) Timestamp() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.timestamp
+}
+
+// HighSequence returns a snapshot of the high sequence of the last generated id
+func (b *Butterfly) HighSequence() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.highSequence
+}
+
+// NodeID returns a snapshot of the node ID of the last generated id
+func (b *Butterfly) NodeID() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.nodeID
+}
+
+// LowSequence returns a snapshot of the low sequence of the last generated id
+func (b *Butterfly) LowSequence() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.lowSequence
+}
+
+// Remaining returns how many more ids Generate can emit before it is exhausted.
+// As a logical generator carries the fields like one 63 bit counter, this is the distance from the last id to the max id.
+// A clock-based generator keeps its node ID, so it only counts the sequences left up to the max timestamp.
+func (b *Butterfly) Remaining() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.remaining()
+}
+
+// remaining is Remaining, the caller must hold the mutex.
+// A clock-based generator counts from the later of its timestamp and the clock, as it jumps to the clock on the next tick.
+func (b *Butterfly) remaining() int64 {
+	if !b.clockBased {
+		return b.layout.maxID() - b.compose()
+	}
+
+	perTick := b.layout.maxIDsPerTick()
+	timestamp, left := b.timestamp, perTick-1-(b.highSequence*(b.layout.maxLowSequence+1)+b.lowSequence)
+	if now := b.now(); now > timestamp {
+		timestamp, left = now, perTick
+	}
+	ticks := b.layout.maxTimestamp - (timestamp - b.epoch)
+	switch {
+	case ticks < 0:
+		return 0
+	case ticks > (math.MaxInt64-left)/perTick:
+		return math.MaxInt64
+	}
+	return ticks*perTick + left
+}
+
+// AdvanceTo moves the generator forward to timestamp and restarts the sequences, e.g. to realign
+// a logical timestamp that raced ahead of the wall clock. Moving backwards would break monotonicity and fails,
+// advancing to the current timestamp changes nothing.
+func (b *Butterfly) AdvanceTo(timestamp int64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if timestamp < b.timestamp {
+		return fmt.Errorf("failed to advance to %d: it is earlier than the current timestamp %d", timestamp, b.timestamp)
+	}
+	if timestamp == b.timestamp {
+		return nil
+	}
+	if timestamp-b.epoch > b.layout.maxTimestamp {
+		return fmt.Errorf("%w: the timestamp %d exceeds the max timestamp %d", ErrTimestampOverflow, timestamp-b.epoch, b.layout.maxTimestamp)
+	}
+
+	b.timestamp = timestamp
+	b.highSequence = 0
+	b.lowSequence = 0
+	if !b.clockBased {
+		b.nodeID = 0
+	}
+	return nil
+}
+
+// Reset returns the generator in place to the starting state of timestamp and nodeID, e.g. to recycle it
+// through a sync.Pool or between test cases. It locks like Generate, but ids generated before the reset
+// may be generated again after it, so only reset a generator whose ids are no longer in use.
+func (b *Butterfly) Reset(timestamp, nodeID int64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := b.layout.validateNodeID(nodeID); err != nil {
+		return err
+	}
+	if b.exclusive && nodeID != b.nodeID {
+		return fmt.Errorf("failed to reset the generator: the exclusive node ID %d cannot be changed", b.nodeID)
+	}
+	if timestamp < b.epoch {
+		return fmt.Errorf("the timestamp %d is earlier than the epoch %d", timestamp, b.epoch)
+	}
+	if timestamp-b.epoch > b.layout.maxTimestamp {
+		return fmt.Errorf("%w: the timestamp %d exceeds the max timestamp %d since the epoch %d", ErrTimestampOverflow, timestamp, b.layout.maxTimestamp, b.epoch)
+	}
+
+	b.timestamp = timestamp
+	b.highSequence = 0
+	b.nodeID = nodeID
+	b.lowSequence = 0
+	b.lastTimestamp = 0
+	b.backfill = nil
+	return nil
+}
+
+// Epoch returns the milliseconds since the Unix epoch that are subtracted from the timestamp before packing
+func (b *Butterfly) Epoch() int64 {
+	return b.epoch
+}
+
+// GenerateInBatches generates count ids under a single lock, the result equals calling Generate count times.
+// If fewer than count ids remain it returns an error without generating any of them.
+// A logical generator never reads the clock, so on a generator from NewDeterministic the result is fully deterministic.
+func (b *Butterfly) GenerateInBatches(count int) ([]int64, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("invalid count %d: count must not be negative", count)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	err := b.throttle(context.Background(), count, func() error {
+		if remaining := b.remaining(); int64(count) > remaining {
+			return fmt.Errorf("%w: %d ids are requested but only %d remain below the max timestamp", ErrTimestampOverflow, count, remaining)
+		}
+		return nil
+	})
+	if err != nil {
+		_, err = b.observe(0, err)
+		return nil, err
+	}
+
+	idList := make([]int64, 0, count)
 	for i := 0; i < count; i++ {
-		idList = append(idList, b.Generate())
+		id, err := b.observe(b.advance())
+		if err != nil {
+			return idList, err
+		}
+		idList = append(idList, id)
 	}
-	return idList
+	return idList, nil
+}
+
+// batchContextInterval is how many ids GenerateInBatchesContext generates between checks of its context
+const batchContextInterval = 4096
+
+// GenerateInBatchesContext is like GenerateInBatches but checks ctx every batchContextInterval ids,
+// returning the ids generated so far along with ctx.Err() once ctx is done. It locks the generator per interval
+// instead of for the whole batch, so the ids of concurrent calls may interleave with the batch.
+func (b *Butterfly) GenerateInBatchesContext(ctx context.Context, count int) ([]int64, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("invalid count %d: count must not be negative", count)
+	}
+
+	idList := make([]int64, 0, count)
+	for len(idList) < count {
+		if err := ctx.Err(); err != nil {
+			return idList, err
+		}
+		n := count - len(idList)
+		if n > batchContextInterval {
+			n = batchContextInterval
+		}
+		batch, err := b.GenerateInBatches(n)
+		idList = append(idList, batch...)
+		if err != nil {
+			return idList, err
+		}
+	}
+	return idList, nil
+}
+
+// Fill writes up to len(dst) ids into dst under a single lock and returns how many it wrote,
+// on exhaustion it returns the count written so far along with the error
+func (b *Butterfly) Fill(dst []int64) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err := b.throttle(context.Background(), len(dst), nil); err != nil {
+		_, err = b.observe(0, err)
+		return 0, err
+	}
+
+	for i := range dst {
+		id, err := b.observe(b.advance())
+		if err != nil {
+			return i, err
+		}
+		dst[i] = id
+	}
+	return len(dst), nil
+}
+
+// Reserve hands the caller a block of n ids no other caller receives, generated under a single lock.
+// On a logical generator Generate carries the fields like one counter, so the block is contiguous: ids[i] equals start+i.
+// A clock-based generator keeps its node ID and follows the clock, so only the ids in the slice are reserved.
+// If fewer than n ids remain it returns an error without reserving any of them.
+func (b *Butterfly) Reserve(n int) (start int64, ids []int64, err error) {
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("invalid n %d: n must be positive", n)
+	}
+
+	ids, err = b.GenerateInBatches(n)
+	if err != nil {
+		return 0, nil, err
+	}
+	return ids[0], ids, nil
 }
 
 type ButterflyList struct {
-	generator    *Butterfly
+	generator    Generator
 	mutex        sync.Mutex
 	UnusedIDList []int64
 	Period       int64
@@ -67,8 +420,10 @@ type ButterflyList struct {
 	IncreaseCount int `validate:"required,gte=3000"`
 }
 
-func (b *ButterflyList) construct() {
-	b.UnusedIDList = append(b.UnusedIDList, b.generator.GenerateInBatches(b.IncreaseCount)...)
+func (b *ButterflyList) construct() error {
+	idList, err := b.generator.GenerateInBatches(b.IncreaseCount)
+	b.UnusedIDList = append(b.UnusedIDList, idList...)
+	return err
 }
 
 func (b *ButterflyList) Consume() int64 {
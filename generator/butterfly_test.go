@@ -1,18 +1,35 @@
 package generator
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+// mustGenerate generates an id and fails the test on error
+func mustGenerate(t testing.TB, b Generator) int64 {
+	t.Helper()
+	id, err := b.Generate()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	return id
+}
+
 func TestButterfly_Generate(t *testing.T) {
 	initTimestamp := time.Now().UnixNano() / int64(time.Millisecond) // 获取当前时间戳，单位毫秒
 	b := NewButterfly(initTimestamp)
 
 	// 测试生成的ID是否递增
-	lastID := b.Generate()
+	lastID := mustGenerate(t, b)
 	for i := 0; i < 1000; i++ {
-		currentID := b.Generate()
+		currentID := mustGenerate(t, b)
 		if currentID <= lastID {
 			t.Errorf("ID not incrementing: %d, %d", currentID, lastID)
 		}
@@ -26,7 +43,7 @@ func TestButterfly_Generate(t *testing.T) {
 	expectedLowSequence := int64(1)
 	expectedHighSequence := int64(0)
 	for i := 0; i < 1000; i++ {
-		id := b.Generate()
+		id := mustGenerate(t, b)
 
 		nodeID := (id >> nodeIDShift) & maxNodeID
 		if nodeID != expectedNodeID {
@@ -63,7 +80,10 @@ func TestButterfly_Generate(t *testing.T) {
 
 func TestButterflyList_Consume(t *testing.T) {
 	initTimestamp := time.Now().UnixNano() / int64(time.Millisecond) // 获取当前时间戳，单位毫秒
-	b := NewButterflyList(initTimestamp)
+	b, err := NewButterflyList(initTimestamp)
+	if err != nil {
+		t.Fatalf("failed to construct the list: %v", err)
+	}
 	consumeCount := 1000
 	balanceAfterFirstConsume := len(b.UnusedIDList) - consumeCount - 1
 	balanceAfterSecondConsume := len(b.UnusedIDList) - 2*consumeCount - 1
@@ -105,3 +125,703 @@ func TestButterflyList_Consume(t *testing.T) {
 	}
 
 }
+
+func TestButterfly_Generate_Monotonic(t *testing.T) {
+	// 测试各个进位边界上生成的ID是否严格递增
+	cases := []struct {
+		name         string
+		timestamp    int64
+		highSequence int64
+		nodeID       int64
+		lowSequence  int64
+		exhausted    bool
+	}{
+		{name: "low sequence overflow", timestamp: 1, lowSequence: maxLowSequence},
+		{name: "node ID overflow", timestamp: 1, nodeID: maxNodeID, lowSequence: maxLowSequence},
+		{name: "high sequence overflow", timestamp: 1, highSequence: maxHighSequence, nodeID: maxNodeID, lowSequence: maxLowSequence},
+		{name: "timestamp boundary", timestamp: maxTimestamp, highSequence: maxHighSequence, nodeID: maxNodeID - 1, lowSequence: maxLowSequence, exhausted: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := NewButterfly(c.timestamp)
+			b.highSequence = c.highSequence
+			b.nodeID = c.nodeID
+			b.lowSequence = c.lowSequence
+			lastID := b.compose()
+
+			for i := 0; i < 1000; i++ {
+				id, err := b.Generate()
+				if err != nil {
+					if !c.exhausted {
+						t.Fatalf("failed to generate id: %v", err)
+					}
+					if i != 2 {
+						t.Errorf("Unexpected exhaustion on %d times loop, expected on 2", i)
+					}
+					return
+				}
+				if id <= lastID {
+					t.Fatalf("ID not incrementing: %d, %d on %d times loop", id, lastID, i)
+				}
+				lastID = id
+			}
+			if c.exhausted {
+				t.Error("exhausted generator expects an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestButterfly_Generate_TimestampBoundary(t *testing.T) {
+	// 测试进位到最大时间戳是否成功
+	b := NewButterfly(maxTimestamp - 1)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	id := mustGenerate(t, b)
+	if expected := int64(maxTimestamp) << timeShift; id != expected {
+		t.Errorf("Unexpected id: %d, expected %d", id, expected)
+	}
+
+	// 测试越过最大时间戳的进位是否立即返回错误且不改变状态
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	last := b.compose()
+	if _, err := b.Generate(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+	if b.timestamp != maxTimestamp || b.compose() != last {
+		t.Errorf("Unexpected state: %d, expected %d", b.compose(), last)
+	}
+
+	// 测试时钟模式下的进位是否同样受限
+	c, err := NewClockBasedWithClock(0, func() int64 { return maxTimestamp })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	c.highSequence = maxHighSequence
+	c.lowSequence = maxLowSequence
+	c.lastTimestamp = maxTimestamp
+	if _, err := c.GenerateWithClock(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+}
+
+func TestButterfly_GenerateInBatches(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+
+	// 测试数量为0时是否返回非nil的空切片
+	idList, err := b.GenerateInBatches(0)
+	if err != nil || idList == nil || len(idList) != 0 {
+		t.Errorf("Unexpected result: %v, %v, expected an empty slice", idList, err)
+	}
+
+	// 测试负数数量是否被拒绝
+	if _, err := b.GenerateInBatches(-1); err == nil {
+		t.Error("negative count expects an error, but got nil")
+	}
+
+	idList, err = b.GenerateInBatches(1000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if len(idList) != 1000 || cap(idList) != 1000 {
+		t.Errorf("Unexpected length %d and capacity %d, expected %d", len(idList), cap(idList), 1000)
+	}
+}
+
+// sequentialGenerator is a deterministic fake returning 1, 2, 3...
+type sequentialGenerator struct {
+	last int64
+}
+
+func (g *sequentialGenerator) Generate() (int64, error) {
+	g.last++
+	return g.last, nil
+}
+
+func (g *sequentialGenerator) GenerateInBatches(count int) ([]int64, error) {
+	idList := make([]int64, 0, count)
+	for i := 0; i < count; i++ {
+		id, _ := g.Generate()
+		idList = append(idList, id)
+	}
+	return idList, nil
+}
+
+func TestNewButterflyListWithGenerator(t *testing.T) {
+	b, err := NewButterflyListWithGenerator(&sequentialGenerator{})
+	if err != nil {
+		t.Fatalf("failed to construct the list: %v", err)
+	}
+
+	// 测试是否消费注入的生成器产生的ID
+	for i := int64(1); i <= 10; i++ {
+		if id := b.Consume(); id != i {
+			t.Errorf("Unexpected id: %d, expected %d", id, i)
+		}
+	}
+}
+
+func TestButterflyList_Refill(t *testing.T) {
+	logger := &recordingLogger{}
+	b, err := New(WithLogger(logger))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	list, err := NewButterflyListWithGenerator(b)
+	if err != nil {
+		t.Fatalf("failed to construct the list: %v", err)
+	}
+
+	// 测试余量不足时是否补充ID，余量充足时是否不补充
+	refillUnusedIDLists()
+	if len(list.UnusedIDList) != list.IncreaseCount {
+		t.Errorf("Unexpected length of unused id list: %d, expected %d", len(list.UnusedIDList), list.IncreaseCount)
+	}
+	list.ConsumeInBatches(list.IncreaseCount - list.AtLeastCount + 1)
+	refillUnusedIDLists()
+	if expected := list.AtLeastCount - 1 + list.IncreaseCount; len(list.UnusedIDList) != expected {
+		t.Errorf("Unexpected length of unused id list: %d, expected %d", len(list.UnusedIDList), expected)
+	}
+
+	// 测试补充失败时是否记录错误且不追加ID
+	list.ConsumeInBatches(len(list.UnusedIDList) - 1)
+	b.mutex.Lock()
+	b.timestamp, b.highSequence, b.nodeID, b.lowSequence = maxTimestamp, maxHighSequence, maxNodeID, maxLowSequence
+	b.mutex.Unlock()
+	refillUnusedIDLists()
+	if len(list.UnusedIDList) != 1 {
+		t.Errorf("Unexpected length of unused id list: %d, expected %d", len(list.UnusedIDList), 1)
+	}
+	if len(logger.lines) != 1 {
+		t.Errorf("Unexpected log lines: %v, expected the failed refill", logger.lines)
+	}
+
+	// 测试补充后列表是否未被锁住
+	list.Consume()
+}
+
+func TestButterfly_Getters(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+
+	// 测试快照是否与最近生成的ID一致
+	for i := 0; i < 1000; i++ {
+		id := mustGenerate(t, b)
+		timestamp, highSequence, nodeID, lowSequence, _ := ParseID(id)
+		if b.Timestamp() != timestamp || b.HighSequence() != highSequence || b.NodeID() != nodeID || b.LowSequence() != lowSequence {
+			t.Fatalf("Unexpected snapshot: %d/%d/%d/%d, expected %d/%d/%d/%d",
+				b.Timestamp(), b.HighSequence(), b.NodeID(), b.LowSequence(), timestamp, highSequence, nodeID, lowSequence)
+		}
+	}
+}
+
+func TestButterfly_Remaining(t *testing.T) {
+	b := NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID - 2
+
+	// 测试剩余数量是否与实际可生成的数量一致
+	remaining := b.Remaining()
+	if remaining != 5 {
+		t.Errorf("Unexpected remaining: %d, expected %d", remaining, 5)
+	}
+	for i := int64(0); i < remaining; i++ {
+		mustGenerate(t, b)
+	}
+	if b.Remaining() != 0 {
+		t.Errorf("Unexpected remaining: %d, expected %d", b.Remaining(), 0)
+	}
+	if _, err := b.Generate(); err == nil {
+		t.Error("exhausted generator expects an error, but got nil")
+	}
+}
+
+func TestButterfly_GenerateInBatches_MatchesGenerate(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	single := NewButterfly(initTimestamp)
+	batch := NewButterfly(initTimestamp)
+
+	// 测试批量生成是否与逐个生成的结果一致
+	for _, count := range []int{1, 2, 1000, 20000} {
+		idList, err := batch.GenerateInBatches(count)
+		if err != nil {
+			t.Fatalf("failed to generate ids: %v", err)
+		}
+		for i, id := range idList {
+			if expected := mustGenerate(t, single); id != expected {
+				t.Fatalf("Unexpected id: %d, expected %d on %d index of %d", id, expected, i, count)
+			}
+		}
+	}
+
+	// 测试跨越耗尽边界的批量是否不生成任何ID
+	b := NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	if _, err := b.GenerateInBatches(2); err == nil {
+		t.Error("batch crossing the exhaustion boundary expects an error, but got nil")
+	}
+	if b.Remaining() != 1 {
+		t.Errorf("Unexpected remaining: %d, expected %d", b.Remaining(), 1)
+	}
+}
+
+func TestButterfly_Fill(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+	expected := NewButterfly(initTimestamp)
+
+	// 测试填充的ID是否与逐个生成的一致
+	dst := make([]int64, 1000)
+	n, err := b.Fill(dst)
+	if err != nil || n != len(dst) {
+		t.Fatalf("Unexpected result: %d, %v, expected %d ids", n, err, len(dst))
+	}
+	for i, id := range dst {
+		if e := mustGenerate(t, expected); id != e {
+			t.Fatalf("Unexpected id: %d, expected %d on %d index", id, e, i)
+		}
+	}
+
+	// 测试耗尽时是否返回已写入的数量及错误
+	b = NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID - 1
+	n, err = b.Fill(dst)
+	if err == nil {
+		t.Error("exhausted generator expects an error, but got nil")
+	}
+	if n != 3 || dst[2] != maxState {
+		t.Errorf("Unexpected result: %d ids ending with %d, expected 3 ids ending with %d", n, dst[2], int64(maxState))
+	}
+}
+
+func TestButterfly_AdvanceTo(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+	for i := 0; i < 1000; i++ {
+		mustGenerate(t, b)
+	}
+	lastID := mustGenerate(t, b)
+
+	// 测试前进后序列号是否归零且ID仍递增
+	if err := b.AdvanceTo(initTimestamp + 10); err != nil {
+		t.Fatalf("failed to advance: %v", err)
+	}
+	id := mustGenerate(t, b)
+	if id <= lastID {
+		t.Errorf("ID not incrementing: %d, %d", id, lastID)
+	}
+	expected := Components{Timestamp: initTimestamp + 10, LowSequence: 1, Time: time.UnixMilli(initTimestamp + 10)}
+	if c := Decompose(id); c != expected {
+		t.Errorf("Unexpected components: %+v, expected %+v", c, expected)
+	}
+
+	// 测试后退是否被拒绝，原地前进是否无变化
+	if err := b.AdvanceTo(initTimestamp); err == nil {
+		t.Error("moving backwards expects an error, but got nil")
+	}
+	if err := b.AdvanceTo(initTimestamp + 10); err != nil {
+		t.Errorf("advancing to the current timestamp expects no error, but got %v", err)
+	}
+	if next := mustGenerate(t, b); next != id+1 {
+		t.Errorf("Unexpected id: %d, expected %d", next, id+1)
+	}
+}
+
+func TestButterfly_Reserve_ClockBased(t *testing.T) {
+	var now int64 = 1000
+	b, err := NewClockBasedWithClock(1, func() int64 {
+		now++
+		return now
+	})
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试时钟模式下预留的ID是否保持节点ID且不连续
+	start, ids, err := b.Reserve(10)
+	if err != nil {
+		t.Fatalf("failed to reserve ids: %v", err)
+	}
+	if start != ids[0] {
+		t.Errorf("Unexpected start: %d, expected %d", start, ids[0])
+	}
+	for i, id := range ids {
+		if nodeID := Decompose(id).NodeID; nodeID != 1 {
+			t.Errorf("Unexpected node ID of the id %d: %d, expected %d", id, nodeID, 1)
+		}
+		if i > 0 && id <= ids[i-1] {
+			t.Errorf("Unexpected id: %d, expected it above %d", id, ids[i-1])
+		}
+	}
+	if last := ids[len(ids)-1]; last == start+int64(len(ids)-1) {
+		t.Errorf("Unexpected contiguous block: %d..%d, expected the clock to break it", start, last)
+	}
+}
+
+func TestButterfly_Reserve(t *testing.T) {
+	b := NewButterfly(1000)
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+
+	// 测试预留的ID是否连续且不再被生成
+	start, ids, err := b.Reserve(100)
+	if err != nil {
+		t.Fatalf("failed to reserve ids: %v", err)
+	}
+	if len(ids) != 100 {
+		t.Fatalf("Unexpected length of ids: %d, expected %d", len(ids), 100)
+	}
+	for i, id := range ids {
+		if id != start+int64(i) {
+			t.Errorf("Unexpected id: %d, expected %d", id, start+int64(i))
+		}
+	}
+	if next := mustGenerate(t, b); next != start+100 {
+		t.Errorf("Unexpected next id: %d, expected %d", next, start+100)
+	}
+
+	// 测试非法数量与剩余不足是否被拒绝
+	if _, _, err := b.Reserve(0); err == nil {
+		t.Error("zero n expects an error, but got nil")
+	}
+	b = NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	last := b.compose()
+	if _, _, err := b.Reserve(2); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+	if b.compose() != last {
+		t.Errorf("Unexpected state: %d, expected %d", b.compose(), last)
+	}
+}
+
+func TestButterfly_GenerateTimestamped(t *testing.T) {
+	epoch := time.Now().UnixMilli() - 1000
+	b, err := New(WithStartTimestamp(epoch+500), WithEpoch(epoch))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+
+	// 测试返回的时间是否与ID中解析出的时间一致，包括进位之后
+	for i := 0; i < 4; i++ {
+		id, created, err := b.GenerateTimestamped()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if expected := b.ExtractTime(id); !created.Equal(expected) {
+			t.Errorf("Unexpected creation time: %v, expected %v on %d times loop", created, expected, i)
+		}
+	}
+	if _, created, _ := b.GenerateTimestamped(); !created.Equal(time.UnixMilli(epoch + 501)) {
+		t.Errorf("Unexpected creation time: %v, expected %v", created, time.UnixMilli(epoch+501))
+	}
+}
+
+func TestButterfly_Compose_NonNegative(t *testing.T) {
+	// 测试各布局的字段取最大值时打包结果是否仍为非负数
+	for _, cfg := range []Config{DefaultConfig(), CompactConfig(), MicrosecondConfig(), {TimestampBits: 63}} {
+		b, err := New(WithConfig(cfg), WithStartTimestamp(0))
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		b.timestamp = b.layout.maxTimestamp
+		b.highSequence = b.layout.maxHighSequence
+		b.nodeID = b.layout.maxNodeID
+		b.lowSequence = b.layout.maxLowSequence
+		if id := b.compose(); id < 0 || id != b.layout.maxID() {
+			t.Errorf("Unexpected max id of %+v: %d, expected %d", cfg, id, b.layout.maxID())
+		}
+		if _, err := b.Generate(); !errors.Is(err, ErrTimestampOverflow) {
+			t.Errorf("Unexpected error of %+v: %v, expected %v", cfg, err, ErrTimestampOverflow)
+		}
+	}
+}
+
+func TestButterfly_Reset(t *testing.T) {
+	b := NewButterfly(1000)
+	first := mustGenerate(t, b)
+	for i := 0; i < 100; i++ {
+		mustGenerate(t, b)
+	}
+
+	// 测试重置后是否回到初始状态
+	if err := b.Reset(1000, 0); err != nil {
+		t.Fatalf("failed to reset the generator: %v", err)
+	}
+	if id := mustGenerate(t, b); id != first {
+		t.Errorf("Unexpected id: %d, expected %d", id, first)
+	}
+	if err := b.Reset(2000, 5); err != nil {
+		t.Fatalf("failed to reset the generator: %v", err)
+	}
+	if b.Timestamp() != 2000 || b.NodeID() != 5 || b.HighSequence() != 0 || b.LowSequence() != 0 {
+		t.Errorf("Unexpected fields: %d/%d/%d/%d", b.Timestamp(), b.HighSequence(), b.NodeID(), b.LowSequence())
+	}
+
+	// 测试非法的输入是否被拒绝且不改变状态
+	for _, c := range []struct{ timestamp, nodeID int64 }{{-1, 0}, {maxTimestamp + 1, 0}, {1000, -1}, {1000, maxNodeID + 1}} {
+		if err := b.Reset(c.timestamp, c.nodeID); err == nil {
+			t.Errorf("%+v expects an error, but got nil", c)
+		}
+	}
+	if b.Timestamp() != 2000 || b.NodeID() != 5 {
+		t.Errorf("Unexpected fields after invalid resets: %d/%d", b.Timestamp(), b.NodeID())
+	}
+}
+
+func TestButterfly_GenerateUnique(t *testing.T) {
+	b := NewButterfly(1000)
+	issued := NewButterfly(1000)
+	seen := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		seen[mustGenerate(t, issued)] = true
+	}
+
+	// 测试已发放的ID是否被跳过
+	id, err := b.GenerateUnique(func(id int64) bool { return seen[id] })
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if expected := mustGenerate(t, issued); id != expected {
+		t.Errorf("Unexpected id: %d, expected %d", id, expected)
+	}
+
+	// 测试所有ID都已发放时是否返回错误
+	c := NewButterfly(maxTimestamp)
+	c.highSequence = maxHighSequence
+	if _, err := c.GenerateUnique(func(int64) bool { return true }); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+}
+
+func TestButterfly_HappensBefore(t *testing.T) {
+	clockBased, err := NewClockBased(1)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	generators := map[string]func() (int64, error){
+		"Generate":          NewButterfly(time.Now().UnixMilli()).Generate,
+		"GenerateWithClock": clockBased.GenerateWithClock,
+		"AtomicButterfly":   NewAtomicButterfly(time.Now().UnixMilli()).Generate,
+	}
+	for name, generate := range generators {
+		t.Run(name, func(t *testing.T) {
+			// 测试先返回的调用是否总是得到更小的ID，调用前后各取一个全局序号作为先后关系
+			type call struct {
+				start, end uint64
+				id         int64
+			}
+			const goroutines = 8
+			const perGoroutine = 5000
+			var ticket uint64
+			calls := make([][]call, goroutines)
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < perGoroutine; i++ {
+						start := atomic.AddUint64(&ticket, 1)
+						id, err := generate()
+						if err != nil {
+							t.Errorf("failed to generate id: %v", err)
+							return
+						}
+						calls[g] = append(calls[g], call{start: start, end: atomic.AddUint64(&ticket, 1), id: id})
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			var all []call
+			for _, c := range calls {
+				all = append(all, c...)
+			}
+			byEnd := make([]call, len(all))
+			copy(byEnd, all)
+			sort.Slice(byEnd, func(i, j int) bool { return byEnd[i].end < byEnd[j].end })
+			maxIDs := make([]int64, len(byEnd))
+			for i, c := range byEnd {
+				maxIDs[i] = c.id
+				if i > 0 && maxIDs[i-1] > c.id {
+					maxIDs[i] = maxIDs[i-1]
+				}
+			}
+			for _, c := range all {
+				// 所有在本调用开始前返回的调用中的最大ID
+				n := sort.Search(len(byEnd), func(i int) bool { return byEnd[i].end > c.start })
+				if n > 0 && maxIDs[n-1] >= c.id {
+					t.Fatalf("id %d started after an id %d returned, expected a greater one", c.id, maxIDs[n-1])
+				}
+			}
+		})
+	}
+}
+
+func TestButterfly_Close(t *testing.T) {
+	before := runtime.NumGoroutine()
+	b, err := NewWithFloorFile(filepath.Join(t.TempDir(), "floor"), 3)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	mustGenerate(t, b)
+
+	// 测试重复关闭是否安全，且关闭后生成返回ErrClosed
+	for i := 0; i < 2; i++ {
+		if err := b.Close(); err != nil {
+			t.Fatalf("failed to close the generator on %d times: %v", i, err)
+		}
+	}
+	if _, err := b.Generate(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+	}
+	if _, err := b.GenerateWithClock(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+	}
+	if idList, err := b.GenerateInBatches(10); !errors.Is(err, ErrClosed) || len(idList) != 0 {
+		t.Errorf("Unexpected result: %v (%v), expected no id and %v", idList, err, ErrClosed)
+	}
+
+	// 测试关闭后的生成是否不再推进状态
+	c, err := New(WithRateLimit(1, RateLimitWait))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	mustGenerate(t, c)
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close the generator: %v", err)
+	}
+	last := c.compose()
+	start := time.Now()
+	for _, generate := range []func() error{
+		func() error { _, err := c.Generate(); return err },
+		func() error { _, err := c.GenerateWithClock(); return err },
+		func() error { _, err := c.GenerateUnique(func(int64) bool { return false }); return err },
+		func() error { _, _, err := c.GenerateTimestamped(); return err },
+		func() error { _, err := c.Fill(make([]int64, 3)); return err },
+		func() error { _, err := c.GenerateContext(context.Background()); return err },
+		func() error { _, err := c.GenerateAt(c.epoch); return err },
+	} {
+		if err := generate(); !errors.Is(err, ErrClosed) {
+			t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+		}
+	}
+	if c.compose() != last {
+		t.Errorf("Unexpected state: %d, expected %d", c.compose(), last)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Unexpected elapsed time: %v, expected no wait for rate limit tokens", elapsed)
+	}
+
+	// 测试后台goroutine是否已退出
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Unexpected goroutines: %d, expected at most %d", after, before)
+	}
+}
+
+// countdownContext is cancelled once Err has been checked checks times
+type countdownContext struct {
+	context.Context
+	checks int
+}
+
+func (c *countdownContext) Err() error {
+	if c.checks--; c.checks < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestButterfly_GenerateInBatchesContext(t *testing.T) {
+	// 测试未取消时结果是否与GenerateInBatches相同
+	b, c := NewButterfly(1700000000000), NewButterfly(1700000000000)
+	idList, err := b.GenerateInBatchesContext(context.Background(), 10000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	expected, err := c.GenerateInBatches(10000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	for i := range expected {
+		if idList[i] != expected[i] {
+			t.Fatalf("Unexpected id on %d times loop: %d, expected %d", i, idList[i], expected[i])
+		}
+	}
+
+	// 测试中途取消时是否返回已生成的部分与取消原因
+	idList, err = b.GenerateInBatchesContext(&countdownContext{Context: context.Background(), checks: 2}, 1000000)
+	if err != context.Canceled {
+		t.Errorf("Unexpected error: %v, expected %v", err, context.Canceled)
+	}
+	if len(idList) != 2*batchContextInterval {
+		t.Errorf("Unexpected count: %d, expected %d", len(idList), 2*batchContextInterval)
+	}
+	if next := mustGenerate(t, b); next <= idList[len(idList)-1] {
+		t.Errorf("ID not incrementing: %d, %d", next, idList[len(idList)-1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if idList, err := b.GenerateInBatchesContext(ctx, 10); err != context.Canceled || len(idList) != 0 {
+		t.Errorf("Unexpected result: %v (%v), expected no id and %v", idList, err, context.Canceled)
+	}
+}
+
+func TestNewDeterministic(t *testing.T) {
+	// 测试两个确定性生成器逐个与批量生成的ID是否相同，且从不读取时钟
+	var outputs [2][]int64
+	for i := range outputs {
+		b, err := NewDeterministic(1700000000000, 42)
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		b.clock = func() int64 {
+			t.Fatal("deterministic generator expects no clock reading")
+			return 0
+		}
+		for j := 0; j < 100000; j++ {
+			outputs[i] = append(outputs[i], mustGenerate(t, b))
+		}
+		batch, err := b.GenerateInBatches(1000)
+		if err != nil {
+			t.Fatalf("failed to generate ids: %v", err)
+		}
+		outputs[i] = append(outputs[i], batch...)
+	}
+	for i := range outputs[0] {
+		if outputs[0][i] != outputs[1][i] {
+			t.Fatalf("Unexpected id on %d times loop: %d, expected %d", i, outputs[1][i], outputs[0][i])
+		}
+	}
+	if c := Decompose(outputs[0][0]); c.Timestamp != 1700000000000 || c.NodeID != 42 {
+		t.Errorf("Unexpected fields: %d/%d, expected %d/%d", c.Timestamp, c.NodeID, int64(1700000000000), 42)
+	}
+
+	// 测试节点ID只是计数器的起点：起点相邻的生成器很快生成相同的ID
+	b, err := NewDeterministic(1700000000000, 3)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	c, err := NewDeterministic(1700000000000, 4)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	mustGenerate(t, b)
+	mustGenerate(t, b)
+	if id, expected := mustGenerate(t, b), mustGenerate(t, c); id != expected {
+		t.Errorf("Unexpected id: %d, expected %d", id, expected)
+	}
+
+	if _, err := NewDeterministic(1700000000000, maxNodeID+1); err == nil {
+		t.Error("invalid node ID expects an error, but got nil")
+	}
+}
@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultRollbackThreshold is the max clock rollback in milliseconds that GenerateWithClock waits out by default
+const defaultRollbackThreshold = 5
+
+// MaxIDsPerMillisecond returns how many ids GenerateWithClock emits within one millisecond of the default layout
+// before it pushes the timestamp ahead of the clock. Sustained rates above this times 1000 per second
+// make the timestamps race ahead of the wall clock.
+func MaxIDsPerMillisecond() int64 {
+	return defaultLayout.maxIDsPerMillisecond()
+}
+
+// MaxIDsPerMillisecond is like the package level MaxIDsPerMillisecond but honors the layout of the generator
+func (b *Butterfly) MaxIDsPerMillisecond() int64 {
+	return b.layout.maxIDsPerMillisecond()
+}
+
+func (l layout) maxIDsPerMillisecond() int64 {
+	return l.maxIDsPerTick() * l.ticksPerMillisecond()
+}
+
+// maxIDsPerTick returns how many ids of a fixed node fit into one timestamp
+func (l layout) maxIDsPerTick() int64 {
+	return (l.maxHighSequence + 1) * (l.maxLowSequence + 1)
+}
+
+// wallClock is the default clock of generators, returning the current Unix time in milliseconds
+func wallClock() int64 {
+	return time.Now().UnixMilli()
+}
+
+// now reads the current timestamp from the injected clock, or from the wall clock if none is injected
+func (b *Butterfly) now() int64 {
+	if b.clock != nil {
+		return b.clock()
+	}
+	return b.layout.wallClock()
+}
+
+// Skew returns how far the timestamp of the generator is ahead of its clock, negative if it is behind.
+// A growing positive skew means bursts are pushing the timestamp ahead and ids decode to the future.
+func (b *Butterfly) Skew() time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return time.Duration(b.timestamp-b.now()) * b.layout.unit
+}
+
+// GenerateWithClock generates an id whose timestamp follows the wall clock.
+// The node ID stays fixed, the sequences restart whenever the millisecond advances,
+// and when one millisecond runs out of sequences the OverflowPolicy decides what happens.
+// A clock rollback within RollbackThreshold is waited out, a larger one returns an error.
+func (b *Butterfly) GenerateWithClock() (int64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err := b.throttle(context.Background(), 1, nil); err != nil {
+		return b.observe(0, err)
+	}
+	return b.observe(b.nextWithClock())
+}
+
+// GenerateContext is like GenerateWithClock, but when the sequences of the current millisecond are used up
+// it waits for the clock to advance instead of pushing the timestamp ahead, until ctx is done.
+func (b *Butterfly) GenerateContext(ctx context.Context) (int64, error) {
+	b.mutex.Lock()
+	if err := b.throttle(ctx, 1, nil); err != nil {
+		defer b.mutex.Unlock()
+		return b.observe(0, err)
+	}
+	b.mutex.Unlock()
+
+	for {
+		b.mutex.Lock()
+		if b.closed {
+			defer b.mutex.Unlock()
+			return b.observe(0, ErrClosed)
+		}
+		wait := b.timestamp - b.now() + 1
+		if b.highSequence < b.layout.maxHighSequence || b.lowSequence < b.layout.maxLowSequence || wait <= 0 {
+			id, err := b.observe(b.nextWithClock())
+			b.mutex.Unlock()
+			return id, err
+		}
+		b.mutex.Unlock()
+
+		timer := time.NewTimer(time.Duration(wait) * b.layout.unit)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// saturated reports whether the sequences are used up until the clock passes the timestamp
+func (b *Butterfly) saturated() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.highSequence == b.layout.maxHighSequence && b.lowSequence == b.layout.maxLowSequence && b.timestamp >= b.now()
+}
+
+// nextWithClock advances the fields to the next id following the clock, the caller must hold the mutex
+func (b *Butterfly) nextWithClock() (int64, error) {
+	if b.cachedClock && b.lastTimestamp != 0 {
+		switch {
+		case b.lowSequence < b.layout.maxLowSequence:
+			b.lowSequence++
+			return b.compose(), nil
+		case b.highSequence < b.layout.maxHighSequence:
+			b.lowSequence = 0
+			b.highSequence++
+			return b.compose(), nil
+		}
+	}
+
+	now := b.now()
+	for now < b.lastTimestamp {
+		rollback := b.lastTimestamp - now
+		if rollback > b.RollbackThreshold {
+			return 0, fmt.Errorf("the clock moved backwards by %v, exceeding the threshold %v", time.Duration(rollback)*b.layout.unit, time.Duration(b.RollbackThreshold)*b.layout.unit)
+		}
+		b.logf("the clock moved backwards by %v, waiting it out", time.Duration(rollback)*b.layout.unit)
+		time.Sleep(time.Duration(rollback) * b.layout.unit)
+		now = b.now()
+	}
+	b.lastTimestamp = now
+
+	if now > b.timestamp {
+		return b.tick(now)
+	}
+	b.randomTick = false
+
+	switch {
+	case b.lowSequence < b.layout.maxLowSequence:
+		b.lowSequence++
+	case b.highSequence < b.layout.maxHighSequence:
+		b.lowSequence = 0
+		b.highSequence++
+	case b.overflowPolicy == PolicyError:
+		return 0, fmt.Errorf("%w: the sequences of the timestamp %d are used up", ErrExhausted, b.timestamp)
+	case b.overflowPolicy == PolicyWaitClock:
+		for now <= b.timestamp {
+			time.Sleep(time.Duration(b.timestamp-now+1) * b.layout.unit)
+			now = b.now()
+		}
+		b.lastTimestamp = now
+		return b.tick(now)
+	default:
+		if b.timestamp-b.epoch >= b.layout.maxTimestamp {
+			return 0, fmt.Errorf("%w: the timestamp %d is the max timestamp and cannot be carried", ErrTimestampOverflow, b.timestamp-b.epoch)
+		}
+		b.logf("the sequences of the timestamp %d are used up, pushing the timestamp ahead of the clock %d", b.timestamp, now)
+		b.observeOverflow()
+		b.lowSequence = 0
+		b.highSequence = 0
+		b.timestamp++
+	}
+	return b.compose(), nil
+}
+
+// tick moves the timestamp to a later clock reading and restarts the sequences, the caller must hold the mutex
+func (b *Butterfly) tick(now int64) (int64, error) {
+	if now-b.epoch > b.layout.maxTimestamp {
+		return 0, fmt.Errorf("%w: the timestamp %d of the clock exceeds the max timestamp %d", ErrTimestampOverflow, now-b.epoch, b.layout.maxTimestamp)
+	}
+	b.timestamp = now
+	b.highSequence = 0
+	b.lowSequence = 0
+	if b.randomTick {
+		b.randomTick = false
+		if err := b.randomizeSequences(); err != nil {
+			return 0, err
+		}
+	}
+	return b.compose(), nil
+}
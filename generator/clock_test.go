@@ -0,0 +1,378 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestButterfly_GenerateWithClock(t *testing.T) {
+	before := time.Now().UnixMilli()
+	b, err := NewClockBased(12)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试生成的ID是否递增且节点ID保持不变
+	var lastID int64
+	for i := 0; i < 10000; i++ {
+		id, err := b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if id <= lastID {
+			t.Errorf("ID not incrementing: %d, %d", id, lastID)
+		}
+		lastID = id
+
+		timestamp, _, nodeID, _, _ := ParseID(id)
+		if nodeID != 12 {
+			t.Errorf("Unexpected node ID: %d, expected %d on %d times loop", nodeID, 12, i)
+		}
+		if timestamp < before {
+			t.Errorf("Unexpected timestamp: %d, expected at least %d on %d times loop", timestamp, before, i)
+		}
+	}
+
+}
+
+func TestNewClockBased_NodeID(t *testing.T) {
+	// 测试节点ID的边界值
+	cases := []struct {
+		nodeID int64
+		valid  bool
+	}{
+		{nodeID: 0, valid: true},
+		{nodeID: maxNodeID, valid: true},
+		{nodeID: maxNodeID + 1, valid: false},
+		{nodeID: -1, valid: false},
+	}
+	for _, c := range cases {
+		b, err := NewClockBased(c.nodeID)
+		if !c.valid {
+			if err == nil {
+				t.Errorf("node ID %d expects an error, but got nil", c.nodeID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("node ID %d expects no error, but got %v", c.nodeID, err)
+			continue
+		}
+
+		id, err := b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if _, _, nodeID, _, _ := ParseID(id); nodeID != c.nodeID {
+			t.Errorf("Unexpected node ID: %d, expected %d", nodeID, c.nodeID)
+		}
+	}
+}
+
+func TestButterfly_GenerateWithClock_Rollback(t *testing.T) {
+	readings := []int64{999, 1000, 997, 998, 999, 1000, 1001, 900}
+	b, err := NewClockBasedWithClock(1, func() int64 {
+		now := readings[0]
+		readings = readings[1:]
+		return now
+	})
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试小幅回拨是否等待时钟追上
+	first, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	second, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("small rollback expects no error, but got %v", err)
+	}
+	if second <= first {
+		t.Errorf("ID not incrementing: %d, %d", second, first)
+	}
+	third, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if timestamp, _, _, _, _ := ParseID(third); timestamp != 1001 {
+		t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, 1001)
+	}
+
+	// 测试大幅回拨是否返回错误
+	if _, err := b.GenerateWithClock(); err == nil {
+		t.Error("large rollback expects an error, but got nil")
+	}
+}
+
+func TestNewGeneratorWithClock(t *testing.T) {
+	b := NewGeneratorWithClock(func() int64 { return 1700000000000 })
+
+	// 测试注入的时钟是否决定生成的ID
+	id := mustGenerate(t, b)
+	expected := int64(1700000000000)<<timeShift | 1
+	if id != expected {
+		t.Errorf("Unexpected id: %d, expected %d", id, expected)
+	}
+}
+
+func TestButterfly_GenerateContext(t *testing.T) {
+	var now int64 = 1000
+	b, err := NewClockBasedWithClock(3, func() int64 { return atomic.LoadInt64(&now) })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.lowSequence = maxLowSequence
+
+	// 测试当前毫秒的序列号用尽时是否等待且响应取消
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := b.GenerateContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error: %v, expected %v", err, context.DeadlineExceeded)
+	}
+	if b.Timestamp() != 1000 {
+		t.Errorf("Unexpected timestamp: %d, expected %d", b.Timestamp(), 1000)
+	}
+
+	// 测试时钟前进后是否生成新毫秒的ID
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		atomic.StoreInt64(&now, 1001)
+	}()
+	id, err := b.GenerateContext(context.Background())
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if timestamp, highSequence, _, lowSequence, _ := ParseID(id); timestamp != 1001 || highSequence != 0 || lowSequence != 0 {
+		t.Errorf("Unexpected fields: %d/%d/%d, expected %d/%d/%d", timestamp, highSequence, lowSequence, 1001, 0, 0)
+	}
+}
+
+func TestButterfly_GenerateWithClock_ResetPerMillisecond(t *testing.T) {
+	var now int64 = 1000
+	b, err := NewClockBasedWithClock(5, func() int64 { return now })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试同一毫秒内序列号递增，进入新毫秒后序列号归零
+	for tick := int64(1001); tick <= 1002; tick++ {
+		now = tick
+		for i := int64(0); i < 300; i++ {
+			id, err := b.GenerateWithClock()
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			timestamp, highSequence, _, lowSequence, _ := ParseID(id)
+			if i == 0 && (timestamp != tick || highSequence != 0 || lowSequence != 0) {
+				t.Errorf("Unexpected first fields of tick %d: %d/%d/%d, expected %d/0/0", tick, timestamp, highSequence, lowSequence, tick)
+			}
+			if timestamp != tick || highSequence*(maxLowSequence+1)+lowSequence != i {
+				t.Errorf("Unexpected sequence on %d times loop of tick %d: %d/%d", i, tick, highSequence, lowSequence)
+			}
+		}
+	}
+}
+
+func TestMaxIDsPerMillisecond(t *testing.T) {
+	if n := MaxIDsPerMillisecond(); n != 512 {
+		t.Errorf("Unexpected max ids per millisecond: %d, expected %d", n, 512)
+	}
+
+	// 测试一毫秒内恰好生成上限数量的ID后才推进时间戳
+	var now int64 = 1000
+	b, err := NewClockBasedWithClock(1, func() int64 { return now })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	now = 1001
+	for i := int64(0); i < b.MaxIDsPerMillisecond(); i++ {
+		id, err := b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if timestamp := Decompose(id).Timestamp; timestamp != now {
+			t.Fatalf("Unexpected timestamp on %d times loop: %d, expected %d", i, timestamp, now)
+		}
+	}
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if timestamp := Decompose(id).Timestamp; timestamp != now+1 {
+		t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, now+1)
+	}
+
+	// 测试自定义布局下的上限
+	cfg := DefaultConfig()
+	cfg.LowSequenceBits, cfg.NodeBits = 4, 10
+	c, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if n := c.MaxIDsPerMillisecond(); n != 4096 {
+		t.Errorf("Unexpected max ids per millisecond: %d, expected %d", n, 4096)
+	}
+}
+
+func TestButterfly_Skew(t *testing.T) {
+	var now int64 = 1000
+	b := NewGeneratorWithClock(func() int64 { return now })
+	if skew := b.Skew(); skew != 0 {
+		t.Errorf("Unexpected skew: %v, expected %v", skew, time.Duration(0))
+	}
+
+	// 测试时间戳被逻辑推进后偏差是否为正
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	mustGenerate(t, b)
+	if skew := b.Skew(); skew != time.Millisecond {
+		t.Errorf("Unexpected skew: %v, expected %v", skew, time.Millisecond)
+	}
+
+	// 测试时钟超过时间戳后偏差是否为负
+	now = 1010
+	if skew := b.Skew(); skew != -9*time.Millisecond {
+		t.Errorf("Unexpected skew: %v, expected %v", skew, -9*time.Millisecond)
+	}
+}
+
+func TestButterfly_GenerateWithClock_DistinctNodes(t *testing.T) {
+	const generators = 1000
+	perGenerator := 1000
+	if testing.Short() {
+		perGenerator = 100
+	}
+
+	// 测试大量不同节点ID的生成器并发生成的ID是否无重复
+	var mutex sync.Mutex
+	seen := make(map[int64]struct{}, generators*perGenerator)
+	var wg sync.WaitGroup
+	for nodeID := int64(0); nodeID < generators; nodeID++ {
+		b, err := NewClockBased(nodeID)
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		wg.Add(1)
+		go func(b *Butterfly) {
+			defer wg.Done()
+			idList := make([]int64, 0, perGenerator)
+			for i := 0; i < perGenerator; i++ {
+				id, err := b.GenerateWithClock()
+				if err != nil {
+					t.Errorf("failed to generate id: %v", err)
+					return
+				}
+				idList = append(idList, id)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, id := range idList {
+				seen[id] = struct{}{}
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	if len(seen) != generators*perGenerator {
+		t.Errorf("Unexpected unique ids: %d, expected %d", len(seen), generators*perGenerator)
+	}
+}
+
+func TestWithCachedClock(t *testing.T) {
+	var now, reads int64 = 1000, 0
+	b, err := New(WithClockMode(), WithNodeID(7), WithCachedClock(), WithClock(func() int64 {
+		reads++
+		return now
+	}))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试缓存的时钟在序列号用尽时才重新读取，且饱和后不产生重复ID
+	now, reads = 1001, 0
+	seen := make(map[int64]bool)
+	for i := int64(0); i < 3*b.MaxIDsPerMillisecond(); i++ {
+		id, err := b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d on %d times loop", id, i)
+		}
+		seen[id] = true
+		if timestamp := Decompose(id).Timestamp; timestamp != now+i/b.MaxIDsPerMillisecond() {
+			t.Fatalf("Unexpected timestamp on %d times loop: %d, expected %d", i, timestamp, now+i/b.MaxIDsPerMillisecond())
+		}
+	}
+	if reads != 3 {
+		t.Errorf("Unexpected clock reads: %d, expected %d", reads, 3)
+	}
+}
+
+func TestButterfly_Generate_ClockBasedKeepsNodeID(t *testing.T) {
+	var now int64 = 1000
+	b, err := NewClockBasedWithClock(5, func() int64 { return now })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试时钟模式下Generate、GenerateInBatches与Fill是否都保持节点ID不变
+	now++
+	idList := []int64{mustGenerate(t, b), mustGenerate(t, b), mustGenerate(t, b), mustGenerate(t, b)}
+	batch, err := b.GenerateInBatches(2000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	filled := make([]int64, 100)
+	if _, err := b.Fill(filled); err != nil {
+		t.Fatalf("failed to fill ids: %v", err)
+	}
+	idList = append(append(idList, batch...), filled...)
+
+	var last int64
+	for i, id := range idList {
+		if nodeID := Decompose(id).NodeID; nodeID != 5 {
+			t.Fatalf("Unexpected node ID: %d, expected %d on %d times loop", nodeID, 5, i)
+		}
+		if id <= last {
+			t.Fatalf("ID not incrementing: %d, %d on %d times loop", id, last, i)
+		}
+		last = id
+	}
+	if b.NodeID() != 5 {
+		t.Errorf("Unexpected node ID: %d, expected %d", b.NodeID(), 5)
+	}
+}
+
+func TestButterfly_GenerateInBatches_ClockBasedRemaining(t *testing.T) {
+	b, err := NewClockBasedWithClock(1, func() int64 { return maxTimestamp })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	mustGenerate(t, b)
+
+	// 测试时钟模式下剩余数量是否只计算本节点的序列号
+	// 构造时的序列号0与第一次生成各占一个
+	left := defaultLayout.maxIDsPerTick() - 2
+	if remaining := b.Remaining(); remaining != left {
+		t.Errorf("Unexpected remaining: %d, expected %d", remaining, left)
+	}
+	if idList, err := b.GenerateInBatches(int(left) + 1); !errors.Is(err, ErrTimestampOverflow) || len(idList) != 0 {
+		t.Errorf("Unexpected result: %d ids (%v), expected no id and %v", len(idList), err, ErrTimestampOverflow)
+	}
+	if _, err := b.GenerateInBatches(int(left)); err != nil {
+		t.Errorf("failed to generate ids: %v", err)
+	}
+	if err := b.Healthy(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+}
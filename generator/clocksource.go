@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock is a source of Unix time in milliseconds for generators, injected with WithClockSource
+type Clock interface {
+	NowMilli() int64
+}
+
+// WallClock reads the wall clock, which NTP may step backwards, the default of generators without a clock
+type WallClock struct{}
+
+// NowMilli returns the current Unix time in milliseconds
+func (WallClock) NowMilli() int64 {
+	return wallClock()
+}
+
+// MonotonicClock counts the monotonic time elapsed since its construction on top of the wall clock read then,
+// so it never moves backwards when NTP steps the wall clock, at the cost of drifting from it over long uptimes
+type MonotonicClock struct {
+	base      time.Time // 构造时读取的时间，带有单调时钟读数
+	baseMilli int64     // 构造时的毫秒时间戳
+}
+
+// NewMonotonicClock constructs a monotonic clock starting at the current wall clock
+func NewMonotonicClock() *MonotonicClock {
+	now := time.Now()
+	return &MonotonicClock{base: now, baseMilli: now.UnixMilli()}
+}
+
+// NowMilli returns the Unix time in milliseconds at construction plus the monotonic milliseconds elapsed since
+func (c *MonotonicClock) NowMilli() int64 {
+	return c.baseMilli + time.Since(c.base).Milliseconds()
+}
+
+// WithClockSource is like WithClock but reads clock, e.g. NewMonotonicClock() to sidestep clock rollbacks
+// altogether. As clock returns milliseconds, the layout must have a resolution of a millisecond.
+func WithClockSource(clock Clock) Option {
+	return func(b *Butterfly) error {
+		if clock == nil {
+			return errors.New("the clock must not be nil")
+		}
+		b.clock = clock.NowMilli
+		b.milliClock = true
+		return nil
+	}
+}
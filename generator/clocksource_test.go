@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonotonicClock(t *testing.T) {
+	c := NewMonotonicClock()
+
+	// 测试单调时钟是否从不回退，且与墙上时钟接近
+	last := c.NowMilli()
+	deadline := time.Now().Add(20 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		now := c.NowMilli()
+		if now < last {
+			t.Fatalf("the monotonic clock moved backwards: %d, %d", now, last)
+		}
+		last = now
+	}
+	if d := (WallClock{}).NowMilli() - c.NowMilli(); d < -5 || d > 5 {
+		t.Errorf("Unexpected drift from the wall clock: %dms", d)
+	}
+}
+
+func TestWithClockSource(t *testing.T) {
+	b, err := New(WithClockMode(), WithNodeID(4), WithClockSource(NewMonotonicClock()))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试注入的时钟是否驱动时钟模式的生成
+	var lastID int64
+	for i := 0; i < 1000; i++ {
+		id, err := b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if id <= lastID {
+			t.Errorf("ID not incrementing: %d, %d", id, lastID)
+		}
+		lastID = id
+	}
+	if d := time.Since(ExtractTime(lastID)); d < -time.Second || d > time.Second {
+		t.Errorf("Unexpected creation time: %v, expected about now", ExtractTime(lastID))
+	}
+
+	// 测试毫秒时钟与微秒布局的组合以及空时钟是否被拒绝
+	if _, err := New(WithClockSource(WallClock{}), WithConfig(MicrosecondConfig())); err == nil {
+		t.Error("millisecond clock with a microsecond layout expects an error, but got nil")
+	}
+	if _, err := New(WithClockSource(nil)); err == nil {
+		t.Error("nil clock expects an error, but got nil")
+	}
+}
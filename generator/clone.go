@@ -0,0 +1,93 @@
+package generator
+
+import "fmt"
+
+// Clone returns an independent generator with a copy of the current state, layout, clock and observers.
+// The clone emits exactly the ids the original would, so it MUST NOT be used alongside the original
+// unless its node ID differs, use CloneWithNodeID for that. Leases and exclusive claims are not copied,
+// WithRateLimit and WithDuplicateCheck start over with a full bucket and an empty window, Stats start from zero,
+// and the clone of a closed generator is closed.
+func (b *Butterfly) Clone() *Butterfly {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.clone()
+	if b.domains != nil {
+		c.domains = make(map[int64]*Butterfly, len(b.domains))
+		for domain, child := range b.domains {
+			c.domains[domain] = child.Clone()
+		}
+	}
+	return c
+}
+
+// CloneWithNodeID returns a clone of a clock-based generator that uses nodeID instead, ready to generate
+// alongside the original without collisions. Cloning a logical generator fails, as its node ID is part of the counter.
+// With WithDomainBits nodeID is the node ID above the domain bits, like for WithNodeID, and the clone starts without domains.
+func (b *Butterfly) CloneWithNodeID(nodeID int64) (*Butterfly, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, fmt.Errorf("failed to clone the generator: %w", ErrClosed)
+	}
+	if !b.clockBased {
+		return nil, fmt.Errorf("failed to clone the generator: only clock-based generators keep the node ID %d apart", nodeID)
+	}
+	if maxNodeID := b.layout.maxNodeID >> b.domainBits; nodeID < 0 || nodeID > maxNodeID {
+		return nil, fmt.Errorf("failed to clone the generator: %w: the node ID %d is out of range [0, %d]", ErrNodeIDOverflow, nodeID, maxNodeID)
+	}
+	if nodeID == b.nodeID>>b.domainBits {
+		return nil, fmt.Errorf("failed to clone the generator: the node ID %d is used by the original", nodeID)
+	}
+
+	c := b.clone()
+	nodeID <<= b.domainBits
+	c.nodeID = nodeID
+	if b.exclusive {
+		if err := claimNodeID(nodeID); err != nil {
+			return nil, fmt.Errorf("failed to clone the generator: %w", err)
+		}
+		c.exclusive = true
+		c.release = func() error {
+			releaseNodeID(nodeID)
+			return nil
+		}
+	}
+	return c, nil
+}
+
+// clone copies the generator without its lease, the caller must hold the mutex
+func (b *Butterfly) clone() *Butterfly {
+	var backfill map[int64]int64
+	if b.backfill != nil {
+		backfill = make(map[int64]int64, len(b.backfill))
+		for timestamp, used := range b.backfill {
+			backfill[timestamp] = used
+		}
+	}
+	return &Butterfly{
+		timestamp:         b.timestamp,
+		highSequence:      b.highSequence,
+		lowSequence:       b.lowSequence,
+		nodeID:            b.nodeID,
+		clock:             b.clock,
+		lastTimestamp:     b.lastTimestamp,
+		layout:            b.layout,
+		config:            b.config,
+		epoch:             b.epoch,
+		observer:          b.observer,
+		logger:            b.logger,
+		clockBased:        b.clockBased,
+		overflowPolicy:    b.overflowPolicy,
+		cachedClock:       b.cachedClock,
+		domainBits:        b.domainBits,
+		randomTick:        b.randomTick,
+		recent:            b.recent.fresh(),
+		limiter:           b.limiter.fresh(),
+		closed:            b.closed,
+		backfill:          backfill,
+		RollbackThreshold: b.RollbackThreshold,
+		MaxSkew:           b.MaxSkew,
+	}
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestButterfly_Clone(t *testing.T) {
+	b := NewButterfly(1000)
+	mustGenerate(t, b)
+
+	// 测试克隆是否继续原生成器的状态且互不影响
+	c := b.Clone()
+	for i := 0; i < 10; i++ {
+		if id, expected := mustGenerate(t, c), mustGenerate(t, b); id != expected {
+			t.Errorf("Unexpected id: %d, expected %d", id, expected)
+		}
+	}
+	mustGenerate(t, c)
+	if c.compose() == b.compose() {
+		t.Error("clone expects its own state, but shares it with the original")
+	}
+}
+
+func TestButterfly_CloneWithNodeID(t *testing.T) {
+	var now int64 = 1000
+	b, err := New(WithClockMode(), WithNodeID(1), WithClock(func() int64 { return now }), WithExclusiveNodeID())
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	defer b.Close()
+
+	// 测试克隆与原生成器并行生成是否无冲突
+	c, err := b.CloneWithNodeID(2)
+	if err != nil {
+		t.Fatalf("failed to clone the generator: %v", err)
+	}
+	seen := make(map[int64]bool)
+	for i := 0; i < 2000; i++ {
+		if i%100 == 0 {
+			now++
+		}
+		for _, g := range []*Butterfly{b, c} {
+			id, err := g.GenerateWithClock()
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			if seen[id] {
+				t.Fatalf("duplicate id %d on %d times loop", id, i)
+			}
+			seen[id] = true
+		}
+	}
+
+	// 测试非法的节点ID与独占冲突是否被拒绝
+	for _, nodeID := range []int64{1, 2, -1, maxNodeID + 1} {
+		if _, err := b.CloneWithNodeID(nodeID); err == nil {
+			t.Errorf("node ID %d expects an error, but got nil", nodeID)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close the clone: %v", err)
+	}
+	if d, err := b.CloneWithNodeID(2); err != nil {
+		t.Errorf("released node ID expects no error, but got %v", err)
+	} else {
+		d.Close()
+	}
+
+	// 测试逻辑模式的生成器是否拒绝克隆
+	if _, err := NewButterfly(1000).CloneWithNodeID(2); err == nil {
+		t.Error("logical generator expects an error, but got nil")
+	}
+}
+
+func TestButterfly_CloneOptions(t *testing.T) {
+	b, err := New(WithClockMode(), WithNodeID(1), WithRateLimit(2, RateLimitError), WithDuplicateCheck(10))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试克隆是否保留速率限制，且令牌桶独立
+	mustGenerate(t, b)
+	mustGenerate(t, b)
+	c := b.Clone()
+	d, err := b.CloneWithNodeID(2)
+	if err != nil {
+		t.Fatalf("failed to clone the generator: %v", err)
+	}
+	for _, g := range []*Butterfly{c, d} {
+		mustGenerate(t, g)
+		timestamp, highSequence, lowSequence := g.timestamp, g.highSequence, g.lowSequence
+		mustGenerate(t, g)
+
+		// 测试克隆是否保留重复检查
+		g.timestamp, g.highSequence, g.lowSequence = timestamp, highSequence, lowSequence
+		g.lastTimestamp = timestamp
+		g.clock = func() int64 { return timestamp }
+		if _, err := g.Generate(); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+		}
+		g.limiter = nil
+		if _, err := g.Generate(); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Unexpected error: %v, expected %v", err, ErrDuplicate)
+		}
+	}
+
+	// 测试已关闭的生成器的克隆是否仍然关闭
+	if err := b.Close(); err != nil {
+		t.Fatalf("failed to close the generator: %v", err)
+	}
+	if _, err := b.Clone().Generate(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+	}
+	if _, err := b.CloneWithNodeID(3); !errors.Is(err, ErrClosed) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+	}
+}
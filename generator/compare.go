@@ -0,0 +1,73 @@
+package generator
+
+import "sort"
+
+// CompareByTime compares only the timestamps of two ids produced with the default layout and epoch,
+// returning -1, 0 or +1. Raw comparison also orders ids of the same millisecond by their sequences and node IDs,
+// which says nothing about which was created first across nodes, so CompareByTime treats them as equal instead.
+func CompareByTime(a, b int64) int {
+	return defaultLayout.compareByTime(a, b)
+}
+
+// CompareByTime is like the package level CompareByTime but honors the layout of the generator
+func (b *Butterfly) CompareByTime(x, y int64) int {
+	return b.layout.compareByTime(x, y)
+}
+
+func (l layout) compareByTime(a, b int64) int {
+	ta := (a >> l.timeShift) & l.maxTimestamp
+	tb := (b >> l.timeShift) & l.maxTimestamp
+	switch {
+	case ta < tb:
+		return -1
+	case ta > tb:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CompareAbsolute compares the creation times of two ids of the default layout produced with the epochs aEpoch
+// and bEpoch, returning -1, 0 or +1. Merging ids of services with different epochs needs it, as a raw comparison
+// or CompareByTime then orders the ids by their offsets from unrelated epochs.
+func CompareAbsolute(a, aEpoch, b, bEpoch int64) int {
+	ta, _, _, _ := defaultLayout.fields(a, aEpoch)
+	tb, _, _, _ := defaultLayout.fields(b, bEpoch)
+	switch {
+	case ta < tb:
+		return -1
+	case ta > tb:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SortByCreation sorts ids of the default layout and epoch by timestamp, then high sequence, then low sequence,
+// approximating the creation order across nodes, which raw sorting loses as the node ID sits above the low sequence.
+// Ids equal in all three keep a deterministic order by their raw value.
+func SortByCreation(ids []int64) {
+	defaultLayout.sortByCreation(ids)
+}
+
+// SortByCreation is like the package level SortByCreation but honors the layout of the generator
+func (b *Butterfly) SortByCreation(ids []int64) {
+	b.layout.sortByCreation(ids)
+}
+
+func (l layout) sortByCreation(ids []int64) {
+	sort.Slice(ids, func(i, j int) bool {
+		ta, ha, _, la := l.fields(ids[i], 0)
+		tb, hb, _, lb := l.fields(ids[j], 0)
+		switch {
+		case ta != tb:
+			return ta < tb
+		case ha != hb:
+			return ha < hb
+		case la != lb:
+			return la < lb
+		default:
+			return ids[i] < ids[j]
+		}
+	})
+}
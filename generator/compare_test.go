@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestCompareByTime(t *testing.T) {
+	now := time.Now().UnixMilli()
+	clock := func() int64 { return now }
+	b1, err := NewClockBasedWithClock(2, clock)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b2, err := NewClockBasedWithClock(1, clock)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试同一毫秒内不同节点的ID是否按时间视为相等，而原始比较并非如此
+	first, _ := b1.GenerateWithClock()
+	second, _ := b2.GenerateWithClock()
+	if first <= second {
+		t.Fatalf("Unexpected raw order: %d, %d, expected the node ID to order the first id after the second", first, second)
+	}
+	if c := CompareByTime(first, second); c != 0 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, 0)
+	}
+
+	// 测试不同毫秒的ID是否按时间排序
+	now++
+	later, _ := b2.GenerateWithClock()
+	if c := CompareByTime(first, later); c != -1 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, -1)
+	}
+	if c := CompareByTime(later, first); c != 1 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, 1)
+	}
+	if c := b1.CompareByTime(later, first); c != 1 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, 1)
+	}
+}
+
+func TestSortByCreation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LowSequenceBits, cfg.NodeBits = 4, 10
+	var now int64 = 1000
+	clock := func() int64 { return now }
+
+	// 测试多个节点交替生成的ID打乱后是否恢复创建顺序
+	var generators []*Butterfly
+	for nodeID := int64(3); nodeID > 0; nodeID-- {
+		b, err := New(WithClockMode(), WithConfig(cfg), WithNodeID(nodeID), WithClock(clock))
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		generators = append(generators, b)
+	}
+	now++
+	var created []int64
+	for i := 0; i < 5; i++ {
+		for _, b := range generators {
+			id, err := b.GenerateWithClock()
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			created = append(created, id)
+		}
+	}
+
+	ids := make([]int64, len(created))
+	copy(ids, created)
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	generators[0].SortByCreation(ids)
+	for i := range ids {
+		// 同一序列号的ID不分先后，按原始值排列
+		expected := created[i/3*3 : i/3*3+3]
+		sorted := []int64{expected[2], expected[1], expected[0]}
+		if ids[i] != sorted[i%3] {
+			t.Errorf("Unexpected id at %d: %d, expected %d", i, ids[i], sorted[i%3])
+		}
+	}
+}
+
+func TestCompareAbsolute(t *testing.T) {
+	const epochA, epochB = 1577836800000, 1704067200000
+	older, err := NewWithEpoch(1714521600000, epochA)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	newer, err := NewWithEpoch(1714521600001, epochB)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试原始值顺序与实际时间相反时，按绝对时间比较是否正确
+	x, y := mustGenerate(t, older), mustGenerate(t, newer)
+	if x < y {
+		t.Fatalf("ids %d and %d expect to sort wrongly by raw value", x, y)
+	}
+	if c := CompareAbsolute(x, epochA, y, epochB); c != -1 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, -1)
+	}
+	if c := CompareAbsolute(y, epochB, x, epochA); c != 1 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, 1)
+	}
+	if c := CompareAbsolute(x, epochA, x, epochA); c != 0 {
+		t.Errorf("Unexpected comparison: %d, expected %d", c, 0)
+	}
+}
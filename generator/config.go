@@ -0,0 +1,278 @@
+package generator
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// Config describes how the 63 usable bits of an id are split among its fields
+type Config struct {
+	TimestampBits    int // 时间戳位数
+	HighSequenceBits int // 高序列号位数
+	NodeBits         int // 节点ID位数
+	LowSequenceBits  int // 低序列号位数
+	// Resolution is the unit of the timestamp, time.Millisecond if zero or time.Microsecond.
+	// The clock, the epoch and RollbackThreshold of the generator are in this unit as well.
+	Resolution time.Duration
+	// Compact drops the node ID for single-node deployments: NodeBits must be 0, the bits may sum to less than 63,
+	// and a marker bit right above them tells compact ids apart from ids of other layouts
+	Compact bool
+	// SequenceAboveNode packs the low sequence above the node ID instead of below it, so that ids of the same millisecond
+	// sort by their sequences across nodes. Logical generators then carry the node ID before the low sequence.
+	SequenceAboveNode bool
+	// Tiny packs ids into at most 31 bits for consumers limited to int32, the resolution may then also be time.Second
+	Tiny bool
+}
+
+// DefaultConfig returns the 41/8/13/1 bit split used by the package level constants
+func DefaultConfig() Config {
+	return Config{
+		TimestampBits:    timestampBits,
+		HighSequenceBits: highSequenceBits,
+		NodeBits:         nodeBits,
+		LowSequenceBits:  lowSequenceBits,
+	}
+}
+
+// CompactConfig returns the default split without the node ID, whose 51 bit ids including the marker
+// are 16 decimal digits long instead of 19
+func CompactConfig() Config {
+	return Config{
+		TimestampBits:    timestampBits,
+		HighSequenceBits: highSequenceBits,
+		LowSequenceBits:  lowSequenceBits,
+		Compact:          true,
+	}
+}
+
+// MicrosecondConfig returns a split with a 51 bit timestamp in microseconds, 1 high sequence bit,
+// 10 node bits and 1 low sequence bit: up to 4 ids per microsecond of a node instead of 512 per millisecond.
+// Its ids last only 2^51 microseconds, about 71 years, past the epoch instead of about 69 years for 2^41 milliseconds,
+// so since the Unix epoch they run out in 2041, use WithEpoch with an epoch in microseconds to move that.
+func MicrosecondConfig() Config {
+	return Config{
+		TimestampBits:    51,
+		HighSequenceBits: 1,
+		NodeBits:         10,
+		LowSequenceBits:  1,
+		Resolution:       time.Microsecond,
+	}
+}
+
+// TinyConfig returns a 31 bit split for GenerateInt32: a 21 bit timestamp in seconds, 5 high sequence bits,
+// 4 node bits and 1 low sequence bit. That is up to 64 ids per second for each of 16 nodes,
+// and its ids last only 2^21 seconds, about 24 days, past the epoch, so pick a recent epoch in seconds.
+func TinyConfig() Config {
+	return Config{
+		TimestampBits:    21,
+		HighSequenceBits: 5,
+		NodeBits:         4,
+		LowSequenceBits:  1,
+		Resolution:       time.Second,
+		Tiny:             true,
+	}
+}
+
+// layout holds the maxima and shifts computed from a Config
+type layout struct {
+	maxTimestamp      int64         // 时间戳最大值
+	maxHighSequence   int64         // 高序列号最大值
+	maxNodeID         int64         // 节点ID最大值
+	maxLowSequence    int64         // 低序列号最大值
+	timeShift         int           // 时间戳位移量
+	highSequenceShift int           // 高序列号位移量
+	nodeIDShift       int           // 节点ID位移量
+	lowSequenceShift  int           // 低序列号位移量，序列号在节点ID之上时非0
+	marker            int64         // 紧凑布局的标记位，非紧凑布局为0
+	unit              time.Duration // 时间戳精度
+	tiny              bool          // 是否为不超过31位的布局
+}
+
+var defaultLayout = layout{
+	maxTimestamp:      maxTimestamp,
+	maxHighSequence:   maxHighSequence,
+	maxNodeID:         maxNodeID,
+	maxLowSequence:    maxLowSequence,
+	timeShift:         timeShift,
+	highSequenceShift: highSequenceShift,
+	nodeIDShift:       nodeIDShift,
+	unit:              time.Millisecond,
+}
+
+// layout validates the config and computes its maxima and shifts
+func (c Config) layout() (layout, error) {
+	if c.TimestampBits < 1 || c.HighSequenceBits < 0 || c.NodeBits < 0 || c.LowSequenceBits < 0 {
+		return layout{}, fmt.Errorf("invalid config %+v: the timestamp needs at least 1 bit and no field may be negative", c)
+	}
+	unit := c.Resolution
+	if unit == 0 {
+		unit = time.Millisecond
+	}
+	if unit != time.Millisecond && unit != time.Microsecond && (!c.Tiny || unit != time.Second) {
+		return layout{}, fmt.Errorf("invalid config %+v: the resolution must be a millisecond, a microsecond or, for a tiny layout, a second", c)
+	}
+
+	sum := c.TimestampBits + c.HighSequenceBits + c.NodeBits + c.LowSequenceBits
+	var marker int64
+	if c.Tiny {
+		if c.Compact {
+			return layout{}, fmt.Errorf("invalid config %+v: a layout cannot be both tiny and compact", c)
+		}
+		if sum > 31 {
+			return layout{}, fmt.Errorf("invalid config %+v: the bits sum to %d, more than the 31 bits of an int32", c, sum)
+		}
+	} else if c.Compact {
+		if c.NodeBits != 0 {
+			return layout{}, fmt.Errorf("invalid config %+v: a compact layout has no node bits", c)
+		}
+		if sum > 62 {
+			return layout{}, fmt.Errorf("invalid config %+v: the bits sum to %d, leaving no room for the compact marker", c, sum)
+		}
+		marker = 1 << sum
+	} else if sum != 63 {
+		return layout{}, fmt.Errorf("invalid config %+v: the bits sum to %d instead of 63", c, sum)
+	}
+
+	lowSequenceShift, nodeIDShift := 0, c.LowSequenceBits
+	if c.SequenceAboveNode {
+		lowSequenceShift, nodeIDShift = c.NodeBits, 0
+	}
+	return layout{
+		maxTimestamp:      -1 ^ (-1 << c.TimestampBits),
+		maxHighSequence:   -1 ^ (-1 << c.HighSequenceBits),
+		maxNodeID:         -1 ^ (-1 << c.NodeBits),
+		maxLowSequence:    -1 ^ (-1 << c.LowSequenceBits),
+		timeShift:         c.HighSequenceBits + c.NodeBits + c.LowSequenceBits,
+		highSequenceShift: c.NodeBits + c.LowSequenceBits,
+		nodeIDShift:       nodeIDShift,
+		lowSequenceShift:  lowSequenceShift,
+		marker:            marker,
+		unit:              unit,
+		tiny:              c.Tiny,
+	}, nil
+}
+
+// sequenceAboveNode reports whether the low sequence is packed above the node ID
+func (l layout) sequenceAboveNode() bool {
+	return l.lowSequenceShift > 0
+}
+
+// maxID returns the largest id of the layout
+func (l layout) maxID() int64 {
+	if l.marker != 0 {
+		return l.marker | (l.marker - 1)
+	}
+	if l.tiny {
+		return l.maxTimestamp<<l.timeShift | (1<<l.timeShift - 1)
+	}
+	return maxState
+}
+
+// checkMarker rejects ids that do not carry the marker of a compact layout, e.g. ids of another layout
+func (l layout) checkMarker(id int64) error {
+	if l.marker != 0 && id&^(l.marker-1) != l.marker {
+		return fmt.Errorf("invalid id %d: it does not carry the compact marker %d", id, l.marker)
+	}
+	return nil
+}
+
+// validateNodeID checks that a fixed node ID fits into the node bits.
+// Node IDs are never changed after construction in clock mode, so this is the only check needed.
+func (l layout) validateNodeID(nodeID int64) error {
+	if nodeID < 0 || nodeID > l.maxNodeID {
+		return fmt.Errorf("%w: the node ID %d is out of range [0, %d]", ErrNodeIDOverflow, nodeID, l.maxNodeID)
+	}
+	return nil
+}
+
+// ticksPerMillisecond returns how many timestamp units make up a millisecond, 0 for a resolution of a second
+func (l layout) ticksPerMillisecond() int64 {
+	return int64(time.Millisecond / l.unit)
+}
+
+// ticks converts d into the timestamp unit, rounding up so that a coarse resolution never turns it into 0
+func (l layout) ticks(d time.Duration) int64 {
+	return int64((d + l.unit - 1) / l.unit)
+}
+
+// wallClock returns the current Unix time in the timestamp unit
+func (l layout) wallClock() int64 {
+	switch l.unit {
+	case time.Microsecond:
+		return time.Now().UnixMicro()
+	case time.Second:
+		return time.Now().Unix()
+	}
+	return wallClock()
+}
+
+// fromTime converts a time to a timestamp in the timestamp unit
+func (l layout) fromTime(t time.Time) int64 {
+	switch l.unit {
+	case time.Microsecond:
+		return t.UnixMicro()
+	case time.Second:
+		return t.Unix()
+	}
+	return t.UnixMilli()
+}
+
+// toTime converts a timestamp in the timestamp unit to a time
+func (l layout) toTime(timestamp int64) time.Time {
+	switch l.unit {
+	case time.Microsecond:
+		return time.UnixMicro(timestamp)
+	case time.Second:
+		return time.Unix(timestamp, 0)
+	}
+	return time.UnixMilli(timestamp)
+}
+
+// Layout describes the bit layout of ids for code decoding them elsewhere: a field is (id >> Shift) & Max,
+// the timestamp is then in Resolution units since the epoch of the generator. Compact layouts also set Marker.
+type Layout struct {
+	TimestampBits     int           // 时间戳位数
+	HighSequenceBits  int           // 高序列号位数
+	NodeBits          int           // 节点ID位数
+	LowSequenceBits   int           // 低序列号位数
+	TimestampShift    int           // 时间戳位移量
+	HighSequenceShift int           // 高序列号位移量
+	NodeIDShift       int           // 节点ID位移量
+	LowSequenceShift  int           // 低序列号位移量
+	MaxTimestamp      int64         // 时间戳最大值
+	MaxHighSequence   int64         // 高序列号最大值
+	MaxNodeID         int64         // 节点ID最大值
+	MaxLowSequence    int64         // 低序列号最大值
+	Marker            int64         // 紧凑布局的标记位，非紧凑布局为0
+	Resolution        time.Duration // 时间戳精度
+}
+
+// DefaultLayout returns the layout of the package level functions such as ParseID
+func DefaultLayout() Layout {
+	return defaultLayout.export()
+}
+
+// Layout returns the layout the generator was actually configured with
+func (b *Butterfly) Layout() Layout {
+	return b.layout.export()
+}
+
+func (l layout) export() Layout {
+	return Layout{
+		TimestampBits:     bits.Len64(uint64(l.maxTimestamp)),
+		HighSequenceBits:  bits.Len64(uint64(l.maxHighSequence)),
+		NodeBits:          bits.Len64(uint64(l.maxNodeID)),
+		LowSequenceBits:   bits.Len64(uint64(l.maxLowSequence)),
+		TimestampShift:    l.timeShift,
+		HighSequenceShift: l.highSequenceShift,
+		NodeIDShift:       l.nodeIDShift,
+		LowSequenceShift:  l.lowSequenceShift,
+		MaxTimestamp:      l.maxTimestamp,
+		MaxHighSequence:   l.maxHighSequence,
+		MaxNodeID:         l.maxNodeID,
+		MaxLowSequence:    l.maxLowSequence,
+		Marker:            l.marker,
+		Resolution:        l.unit,
+	}
+}
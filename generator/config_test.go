@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWithConfig(t *testing.T) {
+	cfg := Config{TimestampBits: 41, HighSequenceBits: 4, NodeBits: 17, LowSequenceBits: 1}
+	b, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试生成的ID是否符合自定义布局
+	lastID := mustGenerate(t, b)
+	for i := 0; i < 1000; i++ {
+		id := mustGenerate(t, b)
+		if id <= lastID {
+			t.Errorf("ID not incrementing: %d, %d", id, lastID)
+		}
+		lastID = id
+
+		if timestamp := id >> 22; timestamp != b.timestamp {
+			t.Errorf("Unexpected timestamp: %d, expected %d on %d times loop", timestamp, b.timestamp, i)
+		}
+		if nodeID := (id >> 1) & (1<<17 - 1); nodeID != b.nodeID {
+			t.Errorf("Unexpected node ID: %d, expected %d on %d times loop", nodeID, b.nodeID, i)
+		}
+	}
+
+	// 测试非法布局是否被拒绝
+	invalidConfigs := []Config{
+		{TimestampBits: 41, HighSequenceBits: 8, NodeBits: 13, LowSequenceBits: 2},
+		{TimestampBits: 0, HighSequenceBits: 8, NodeBits: 54, LowSequenceBits: 1},
+		{TimestampBits: 42, HighSequenceBits: -1, NodeBits: 21, LowSequenceBits: 1},
+		{TimestampBits: 30, HighSequenceBits: 8, NodeBits: 24, LowSequenceBits: 1},
+	}
+	for _, cfg := range invalidConfigs {
+		if _, err := NewWithConfig(cfg); err == nil {
+			t.Errorf("config %+v expects an error, but got nil", cfg)
+		}
+	}
+}
+
+func TestNewWithConfig_LowSequence(t *testing.T) {
+	cfg := Config{TimestampBits: 41, HighSequenceBits: 4, NodeBits: 8, LowSequenceBits: 10}
+	b, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试加宽后的低序列号是否遍历0到1023后才进位
+	for i := int64(1); i < 1<<10; i++ {
+		id := mustGenerate(t, b)
+		if lowSequence := id & (1<<10 - 1); lowSequence != i {
+			t.Fatalf("Unexpected low sequence: %d, expected %d", lowSequence, i)
+		}
+		if nodeID := (id >> 10) & (1<<8 - 1); nodeID != 0 {
+			t.Fatalf("Unexpected node ID: %d, expected %d", nodeID, 0)
+		}
+	}
+	id := mustGenerate(t, b)
+	if lowSequence, nodeID := id&(1<<10-1), (id>>10)&(1<<8-1); lowSequence != 0 || nodeID != 1 {
+		t.Errorf("Unexpected low sequence %d and node ID %d, expected %d and %d", lowSequence, nodeID, 0, 1)
+	}
+}
+
+func TestCompactConfig(t *testing.T) {
+	b, err := NewWithConfig(CompactConfig())
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试紧凑ID是否更短且可由紧凑布局解析
+	lastID := b.compose()
+	for i := 0; i < 1000; i++ {
+		id := mustGenerate(t, b)
+		if id <= lastID {
+			t.Errorf("ID not incrementing: %d, %d", id, lastID)
+		}
+		lastID = id
+		if id < 1<<50 || id >= 1<<51 {
+			t.Fatalf("Unexpected compact id: %d, expected it within [2^50, 2^51)", id)
+		}
+		timestamp, highSequence, nodeID, lowSequence, err := b.ParseID(id)
+		if err != nil {
+			t.Fatalf("failed to parse id %d: %v", id, err)
+		}
+		if timestamp != b.timestamp || highSequence != b.highSequence || nodeID != 0 || lowSequence != b.lowSequence {
+			t.Errorf("Unexpected fields: %d/%d/%d/%d", timestamp, highSequence, nodeID, lowSequence)
+		}
+		if err := b.layout.validate(id); err != nil {
+			t.Errorf("compact id %d expects no error, but got %v", id, err)
+		}
+	}
+	if remaining := b.Remaining(); remaining != 1<<51-1-lastID {
+		t.Errorf("Unexpected remaining: %d, expected %d", remaining, 1<<51-1-lastID)
+	}
+
+	// 测试紧凑布局是否拒绝其他布局的ID
+	full := mustGenerate(t, NewGeneratorWithNowTime())
+	if _, _, _, _, err := b.ParseID(full); err == nil {
+		t.Errorf("full id %d expects an error, but got nil", full)
+	}
+	if err := b.layout.validate(full); err == nil {
+		t.Errorf("full id %d expects an error, but got nil", full)
+	}
+
+	// 测试非法的紧凑布局与节点ID是否被拒绝
+	for _, cfg := range []Config{
+		{TimestampBits: 41, HighSequenceBits: 8, NodeBits: 1, LowSequenceBits: 1, Compact: true},
+		{TimestampBits: 53, HighSequenceBits: 8, LowSequenceBits: 2, Compact: true},
+	} {
+		if _, err := NewWithConfig(cfg); err == nil {
+			t.Errorf("config %+v expects an error, but got nil", cfg)
+		}
+	}
+	if _, err := New(WithConfig(CompactConfig()), WithNodeID(1)); err == nil {
+		t.Error("node ID of a compact layout expects an error, but got nil")
+	}
+}
+
+func TestMicrosecondConfig(t *testing.T) {
+	// 测试微秒精度的生成器是否读取微秒时钟且解析出正确的时间
+	before := time.Now()
+	b, err := New(WithClockMode(), WithConfig(MicrosecondConfig()), WithNodeID(maxNodeID>>3))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	created := b.ExtractTime(id)
+	if created.Before(before.Truncate(time.Microsecond)) || created.After(time.Now()) {
+		t.Errorf("Unexpected creation time: %v, expected it between %v and now", created, before)
+	}
+	if c := b.Decompose(id); !c.Time.Equal(created) || c.NodeID != maxNodeID>>3 {
+		t.Errorf("Unexpected components: %+v, expected time %v and node ID %d", c, created, maxNodeID>>3)
+	}
+	if b.RollbackThreshold != 5000 {
+		t.Errorf("Unexpected rollback threshold: %d, expected %d", b.RollbackThreshold, 5000)
+	}
+	if n := b.MaxIDsPerMillisecond(); n != 4000 {
+		t.Errorf("Unexpected max ids per millisecond: %d, expected %d", n, 4000)
+	}
+
+	// 测试注入的微秒时钟是否决定时间戳
+	var now int64 = 1700000000123456
+	c, err := New(WithClockMode(), WithConfig(MicrosecondConfig()), WithClock(func() int64 { return now }))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	now++
+	id, err = c.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if created := c.ExtractTime(id); !created.Equal(time.UnixMicro(now)) {
+		t.Errorf("Unexpected creation time: %v, expected %v", created, time.UnixMicro(now))
+	}
+	if skew := c.Skew(); skew != 0 {
+		t.Errorf("Unexpected skew: %v, expected %v", skew, time.Duration(0))
+	}
+
+	cfg := MicrosecondConfig()
+	cfg.Resolution = time.Second
+	if _, err := NewWithConfig(cfg); err == nil {
+		t.Error("invalid resolution expects an error, but got nil")
+	}
+}
+
+func TestLayout(t *testing.T) {
+	// 测试默认布局是否与包级常量一致
+	expected := Layout{
+		TimestampBits: 41, HighSequenceBits: 8, NodeBits: 13, LowSequenceBits: 1,
+		TimestampShift: timeShift, HighSequenceShift: highSequenceShift, NodeIDShift: nodeIDShift,
+		MaxTimestamp: maxTimestamp, MaxHighSequence: maxHighSequence, MaxNodeID: maxNodeID, MaxLowSequence: maxLowSequence,
+		Resolution: time.Millisecond,
+	}
+	if l := DefaultLayout(); l != expected {
+		t.Errorf("Unexpected layout: %+v, expected %+v", l, expected)
+	}
+
+	// 测试自定义布局是否反映实例的配置，且可据此解析ID
+	cfg := Config{TimestampBits: 41, HighSequenceBits: 4, NodeBits: 8, LowSequenceBits: 10}
+	b, err := New(WithConfig(cfg), WithNodeID(77))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	l := b.Layout()
+	if l.TimestampBits != 41 || l.HighSequenceBits != 4 || l.NodeBits != 8 || l.LowSequenceBits != 10 {
+		t.Errorf("Unexpected bits: %+v, expected %+v", l, cfg)
+	}
+	id := mustGenerate(t, b)
+	if nodeID := (id >> l.NodeIDShift) & l.MaxNodeID; nodeID != 77 {
+		t.Errorf("Unexpected node ID: %d, expected %d", nodeID, 77)
+	}
+	if timestamp := (id >> l.TimestampShift) & l.MaxTimestamp; timestamp != b.Timestamp() {
+		t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, b.Timestamp())
+	}
+}
+
+func TestConfig_SequenceAboveNode(t *testing.T) {
+	cfg := Config{TimestampBits: 41, HighSequenceBits: 4, NodeBits: 10, LowSequenceBits: 8, SequenceAboveNode: true}
+	now := time.Now().UnixMilli()
+	var nodes []*Butterfly
+	for nodeID := int64(0); nodeID < 3; nodeID++ {
+		b, err := New(WithConfig(cfg), WithClockMode(), WithNodeID(nodeID), WithClock(func() int64 { return now }))
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		nodes = append(nodes, b)
+	}
+
+	// 测试同一毫秒内不同节点的ID是否按序列号排序，且解析出正确的节点ID
+	now++
+	var last, lastSequence int64 = -1, -1
+	for i := int64(0); i < 100; i++ {
+		for nodeID := len(nodes) - 1; nodeID >= 0; nodeID-- {
+			id, err := nodes[nodeID].GenerateWithClock()
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			timestamp, highSequence, parsedNodeID, lowSequence, err := nodes[nodeID].ParseID(id)
+			if err != nil || timestamp != now || parsedNodeID != int64(nodeID) {
+				t.Errorf("Unexpected fields: %d/%d (%v), expected %d/%d", timestamp, parsedNodeID, err, now, nodeID)
+			}
+			sequence := highSequence<<8 | lowSequence
+			if sequence != i {
+				t.Errorf("Unexpected sequence: %d, expected %d", sequence, i)
+			}
+			if sequence > lastSequence && id <= last {
+				t.Errorf("ID %d of sequence %d does not sort after %d of sequence %d", id, sequence, last, lastSequence)
+			}
+			last, lastSequence = id, sequence
+		}
+	}
+
+	// 测试逻辑模式下先进位节点ID时ID是否仍然递增
+	b, err := New(WithConfig(cfg), WithStartTimestamp(now))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	remaining := b.Remaining()
+	last = 0
+	for i := int64(1); i <= 600000; i++ {
+		id := mustGenerate(t, b)
+		if id <= last {
+			t.Fatalf("ID not incrementing: %d, %d on %d times loop", id, last, i)
+		}
+		last = id
+	}
+	if b.Remaining() != remaining-600000 {
+		t.Errorf("Unexpected remaining: %d, expected %d", b.Remaining(), remaining-600000)
+	}
+	if b.HighSequence() != 2 {
+		t.Errorf("Unexpected high sequence: %d, expected %d", b.HighSequence(), 2)
+	}
+}
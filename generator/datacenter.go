@@ -0,0 +1,53 @@
+package generator
+
+import "fmt"
+
+// DefaultWorkerBits splits the 13 node bits into 5 bits of datacenter and 8 bits of worker
+const DefaultWorkerBits = 8
+
+// NewWithDatacenterWorker constructs a clock-based generator whose node ID is composed of a datacenter and a worker,
+// split at DefaultWorkerBits like classic Snowflake
+func NewWithDatacenterWorker(datacenter, worker int64) (*Butterfly, error) {
+	return NewWithDatacenterWorkerBits(datacenter, worker, DefaultWorkerBits)
+}
+
+// NewWithDatacenterWorkerBits is like NewWithDatacenterWorker but gives the low workerBits of the node ID to the worker
+func NewWithDatacenterWorkerBits(datacenter, worker int64, workerBits int) (*Butterfly, error) {
+	nodeID, err := composeNodeID(datacenter, worker, workerBits)
+	if err != nil {
+		return nil, err
+	}
+	return NewClockBased(nodeID)
+}
+
+// ParseDatacenterWorker returns the datacenter and worker of an id whose node ID is split at workerBits,
+// it fails if workerBits does not fit into the node bits
+func ParseDatacenterWorker(id int64, workerBits int) (datacenter, worker int64, err error) {
+	if err := checkWorkerBits(workerBits); err != nil {
+		return 0, 0, err
+	}
+	_, _, nodeID, _ := defaultLayout.fields(id, 0)
+	return nodeID >> workerBits, nodeID & (-1 ^ (-1 << workerBits)), nil
+}
+
+func checkWorkerBits(workerBits int) error {
+	if workerBits < 0 || workerBits > nodeBits {
+		return fmt.Errorf("the worker bits %d are out of range [0, %d]", workerBits, nodeBits)
+	}
+	return nil
+}
+
+func composeNodeID(datacenter, worker int64, workerBits int) (int64, error) {
+	if err := checkWorkerBits(workerBits); err != nil {
+		return 0, err
+	}
+	maxDatacenter := int64(-1 ^ (-1 << (nodeBits - workerBits)))
+	maxWorker := int64(-1 ^ (-1 << workerBits))
+	if datacenter < 0 || datacenter > maxDatacenter {
+		return 0, fmt.Errorf("%w: the datacenter %d is out of range [0, %d]", ErrNodeIDOverflow, datacenter, maxDatacenter)
+	}
+	if worker < 0 || worker > maxWorker {
+		return 0, fmt.Errorf("%w: the worker %d is out of range [0, %d]", ErrNodeIDOverflow, worker, maxWorker)
+	}
+	return datacenter<<workerBits | worker, nil
+}
@@ -0,0 +1,50 @@
+package generator
+
+import "testing"
+
+func TestNewWithDatacenterWorker(t *testing.T) {
+	b, err := NewWithDatacenterWorker(3, 200)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试数据中心与工作节点是否可以分别解析
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if datacenter, worker, err := ParseDatacenterWorker(id, DefaultWorkerBits); err != nil || datacenter != 3 || worker != 200 {
+		t.Errorf("Unexpected datacenter %d and worker %d (%v), expected %d and %d", datacenter, worker, err, 3, 200)
+	}
+
+	// 测试越界的工作节点位数是否返回错误而非panic
+	for _, workerBits := range []int{-1, nodeBits + 1} {
+		if _, _, err := ParseDatacenterWorker(id, workerBits); err == nil {
+			t.Errorf("worker bits %d expects an error, but got nil", workerBits)
+		}
+	}
+
+	b, err = NewWithDatacenterWorkerBits(1, 5, 3)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.NodeID() != 1<<3|5 {
+		t.Errorf("Unexpected node ID: %d, expected %d", b.NodeID(), 1<<3|5)
+	}
+
+	// 测试越界的数据中心与工作节点是否被拒绝
+	invalid := []struct {
+		datacenter, worker int64
+		workerBits         int
+	}{
+		{datacenter: 32, worker: 0, workerBits: 8},
+		{datacenter: 0, worker: 256, workerBits: 8},
+		{datacenter: -1, worker: 0, workerBits: 8},
+		{datacenter: 0, worker: 0, workerBits: 14},
+	}
+	for _, c := range invalid {
+		if _, err := NewWithDatacenterWorkerBits(c.datacenter, c.worker, c.workerBits); err == nil {
+			t.Errorf("%+v expects an error, but got nil", c)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package generator
+
+import "fmt"
+
+// WithDuplicateCheck remembers the last size ids of the generator and fails any generation repeating one of them,
+// to catch layout or concurrency bugs in tests and staging. It costs a map lookup per id, without it the hot path
+// only checks a nil pointer.
+func WithDuplicateCheck(size int) Option {
+	return func(b *Butterfly) error {
+		if size < 1 {
+			return fmt.Errorf("invalid size %d: at least 1 id must be remembered", size)
+		}
+		b.recent = newRecentIDs(size)
+		return nil
+	}
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{ring: make([]int64, 0, size), seen: make(map[int64]struct{}, size)}
+}
+
+// recentIDs is a ring buffer of the last generated ids with a set for fast lookups
+type recentIDs struct {
+	ring []int64            // 最近生成的ID
+	next int                // 下一个被覆盖的位置
+	seen map[int64]struct{} // ring中的ID集合
+}
+
+// fresh returns an empty window of the same size, or nil for nil
+func (r *recentIDs) fresh() *recentIDs {
+	if r == nil {
+		return nil
+	}
+	return newRecentIDs(cap(r.ring))
+}
+
+// add records id, it returns false if id is already among the recent ids
+func (r *recentIDs) add(id int64) bool {
+	if _, ok := r.seen[id]; ok {
+		return false
+	}
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, id)
+	} else {
+		delete(r.seen, r.ring[r.next])
+		r.ring[r.next] = id
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.seen[id] = struct{}{}
+	return true
+}
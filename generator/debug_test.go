@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithDuplicateCheck(t *testing.T) {
+	b, err := New(WithStartTimestamp(1000), WithDuplicateCheck(16))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		mustGenerate(t, b)
+	}
+
+	// 测试低序列号与节点ID重叠的错误布局是否被发现
+	b.layout.nodeIDShift = 0
+	var dupErr error
+	for i := 0; i < 10 && dupErr == nil; i++ {
+		_, dupErr = b.Generate()
+	}
+	if !errors.Is(dupErr, ErrDuplicate) {
+		t.Errorf("Unexpected error: %v, expected %v", dupErr, ErrDuplicate)
+	}
+
+	if _, err := New(WithDuplicateCheck(0)); err == nil {
+		t.Error("zero size expects an error, but got nil")
+	}
+}
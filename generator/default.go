@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	defaultMutex     sync.Mutex // 保护默认生成器
+	defaultGenerator *Butterfly // 进程内的默认生成器，nil时在下次使用时创建
+)
+
+// Default returns the process-wide clock-based generator, created on first use with a node ID
+// hashed from the hostname. Hosts whose names hash alike share a node ID, use SetDefault to pick one explicitly.
+func Default() *Butterfly {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	if defaultGenerator == nil {
+		hostname, _ := os.Hostname()
+		b, err := NewClockBased(hashNodeID([]byte(hostname)))
+		if err != nil {
+			panic(fmt.Sprintf("failed to construct the default generator: %v", err))
+		}
+		defaultGenerator = b
+	}
+	return defaultGenerator
+}
+
+// SetDefault replaces the generator returned by Default, e.g. to inject a fake clock in tests.
+// nil resets it, so that Default creates a generator from the hostname again on its next use.
+func SetDefault(b *Butterfly) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultGenerator = b
+}
+
+// Generate generates an id with the default generator following the clock
+func Generate() (int64, error) {
+	return Default().GenerateWithClock()
+}
+
+// GenerateInBatches generates count ids with the default generator under a single lock, like Butterfly.GenerateInBatches
+func GenerateInBatches(count int) ([]int64, error) {
+	return Default().GenerateInBatches(count)
+}
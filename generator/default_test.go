@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	// 测试默认生成器是否唯一且节点ID由主机名得出
+	b := Default()
+	if Default() != b {
+		t.Error("Default expects the same generator on every call")
+	}
+	hostname, _ := os.Hostname()
+	if nodeID := b.NodeID(); nodeID != hashNodeID([]byte(hostname)) {
+		t.Errorf("Unexpected node ID: %d, expected %d", nodeID, hashNodeID([]byte(hostname)))
+	}
+
+	// 测试替换后的默认生成器是否被包级函数使用
+	var now int64 = 1000
+	fake, err := NewClockBasedWithClock(7, func() int64 { return now })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	SetDefault(fake)
+	defer SetDefault(b)
+
+	id, err := Generate()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	idList, err := GenerateInBatches(10)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if len(idList) != 10 {
+		t.Fatalf("Unexpected length of idList: %d, expected %d", len(idList), 10)
+	}
+	for _, next := range idList {
+		if next <= id {
+			t.Errorf("ID not incrementing: %d, %d", next, id)
+		}
+		if c := Decompose(next); c.NodeID != 7 || c.Timestamp != now {
+			t.Errorf("Unexpected components: %+v, expected node ID 7 at %d", c, now)
+		}
+		id = next
+	}
+	if _, err := GenerateInBatches(-1); err == nil {
+		t.Error("negative count expects an error, but got nil")
+	}
+
+	// 测试批量生成是否在剩余不足时不生成任何ID
+	fake.mutex.Lock()
+	fake.timestamp, fake.highSequence, fake.lowSequence = maxTimestamp, maxHighSequence, maxLowSequence-1
+	fake.mutex.Unlock()
+	if idList, err := GenerateInBatches(maxLowSequence + 1); err == nil || len(idList) != 0 {
+		t.Errorf("Unexpected result: %v (%v), expected no id and an error", idList, err)
+	}
+
+	// 测试以nil重置后是否重新创建默认生成器
+	SetDefault(nil)
+	if d := Default(); d == nil || d == fake || d.NodeID() != hashNodeID([]byte(hostname)) {
+		t.Errorf("Unexpected default generator after the reset: %v", d)
+	}
+}
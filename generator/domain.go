@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithDomainBits gives the low bits of the node ID of a clock-based generator to a domain, so that separate
+// namespaces such as orders and users share a machine but not a sequence. The node ID given by WithNodeID
+// then must fit into the remaining bits, and GenerateWithClock generates in domain 0.
+func WithDomainBits(bits int) Option {
+	return func(b *Butterfly) error {
+		if bits < 1 {
+			return fmt.Errorf("invalid domain bits %d: at least 1 bit is required", bits)
+		}
+		b.domainBits = bits
+		return nil
+	}
+}
+
+// GenerateInDomain generates an id following the clock whose node ID carries domain in its low bits,
+// every domain counting its own sequences
+func (b *Butterfly) GenerateInDomain(domain int64) (int64, error) {
+	b.mutex.Lock()
+	if maxDomain := int64(-1 ^ (-1 << b.domainBits)); b.domainBits == 0 || domain < 0 || domain > maxDomain {
+		b.mutex.Unlock()
+		return 0, fmt.Errorf("the domain %d is out of range [0, %d]", domain, maxDomain)
+	}
+	if err := b.throttle(context.Background(), 1, nil); err != nil {
+		defer b.mutex.Unlock()
+		return b.observe(0, err)
+	}
+	if domain == 0 {
+		defer b.mutex.Unlock()
+		return b.observe(b.nextWithClock())
+	}
+
+	if child, ok := b.domains[domain]; ok {
+		b.mutex.Unlock()
+		return child.GenerateWithClock()
+	}
+
+	// the first id of a domain takes the sequence 0 of the current timestamp, which no id of its node ID has used
+	defer b.mutex.Unlock()
+	child := b.clone()
+	child.nodeID = b.nodeID | domain
+	child.domainBits = 0
+	// the parent throttles every domain already
+	child.limiter = nil
+	child.highSequence = 0
+	child.lowSequence = 0
+	if b.domains == nil {
+		b.domains = make(map[int64]*Butterfly)
+	}
+	b.domains[domain] = child
+	return child.observe(child.compose(), nil)
+}
+
+// ParseDomain returns the node ID and the domain of an id generated with domainBits,
+// it fails if domainBits leaves no node bits above the domain
+func ParseDomain(id int64, domainBits int) (nodeID, domain int64, err error) {
+	if domainBits < 0 || domainBits >= nodeBits {
+		return 0, 0, fmt.Errorf("the domain bits %d are out of range [0, %d)", domainBits, nodeBits)
+	}
+	_, _, composed, _ := defaultLayout.fields(id, 0)
+	return composed >> domainBits, composed & (-1 ^ (-1 << domainBits)), nil
+}
+
+// applyDomainBits moves the node ID above the domain bits, the caller must hold the mutex or own the generator
+func (b *Butterfly) applyDomainBits() error {
+	if !b.clockBased {
+		return fmt.Errorf("the domain bits %d require the clock mode", b.domainBits)
+	}
+	nodeBits := b.layout.export().NodeBits
+	if b.domainBits >= nodeBits {
+		return fmt.Errorf("invalid domain bits %d: they must leave some of the %d node bits to the node ID", b.domainBits, nodeBits)
+	}
+	if maxNodeID := b.layout.maxNodeID >> b.domainBits; b.nodeID < 0 || b.nodeID > maxNodeID {
+		return fmt.Errorf("%w: the node ID %d is out of range [0, %d] beside %d domain bits", ErrNodeIDOverflow, b.nodeID, maxNodeID, b.domainBits)
+	}
+	b.nodeID <<= b.domainBits
+	return nil
+}
@@ -0,0 +1,110 @@
+package generator
+
+import "testing"
+
+func TestButterfly_GenerateInDomain(t *testing.T) {
+	var now int64 = 1000
+	b, err := New(WithClockMode(), WithNodeID(5), WithDomainBits(2), WithClock(func() int64 { return now }))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试各域是否独立计数且ID互不重复
+	now++
+	seen := make(map[int64]bool)
+	for i := int64(0); i < 100; i++ {
+		for domain := int64(0); domain < 4; domain++ {
+			id, err := b.GenerateInDomain(domain)
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			if seen[id] {
+				t.Fatalf("duplicate id %d on %d times loop", id, i)
+			}
+			seen[id] = true
+
+			if nodeID, d, err := ParseDomain(id, 2); err != nil || nodeID != 5 || d != domain {
+				t.Errorf("Unexpected node ID and domain: %d/%d (%v), expected %d/%d", nodeID, d, err, 5, domain)
+			}
+			if c := Decompose(id); c.HighSequence*(maxLowSequence+1)+c.LowSequence != i {
+				t.Errorf("Unexpected sequence of domain %d on %d times loop: %d/%d", domain, i, c.HighSequence, c.LowSequence)
+			}
+		}
+	}
+
+	// 测试非法的域与配置是否被拒绝
+	for _, domain := range []int64{-1, 4} {
+		if _, err := b.GenerateInDomain(domain); err == nil {
+			t.Errorf("domain %d expects an error, but got nil", domain)
+		}
+	}
+	if _, err := NewButterfly(1000).GenerateInDomain(0); err == nil {
+		t.Error("generator without domain bits expects an error, but got nil")
+	}
+	for _, opts := range [][]Option{
+		{WithDomainBits(2)},
+		{WithClockMode(), WithDomainBits(13)},
+		{WithClockMode(), WithDomainBits(2), WithNodeID(maxNodeID >> 1)},
+		{WithClockMode(), WithDomainBits(0)},
+	} {
+		if _, err := New(opts...); err == nil {
+			t.Errorf("options %d expect an error, but got nil", len(opts))
+		}
+	}
+	for _, domainBits := range []int{-1, nodeBits} {
+		if _, _, err := ParseDomain(0, domainBits); err == nil {
+			t.Errorf("domain bits %d expects an error, but got nil", domainBits)
+		}
+	}
+}
+
+func TestButterfly_CloneInDomains(t *testing.T) {
+	var now int64 = 1000
+	b, err := New(WithClockMode(), WithNodeID(5), WithDomainBits(2), WithClock(func() int64 { return now }))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	now++
+	if _, err := b.GenerateInDomain(1); err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+
+	// 测试克隆是否保留域，且与原生成器生成相同的ID
+	c := b.Clone()
+	for i := 0; i < 10; i++ {
+		id, err := c.GenerateInDomain(1)
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if expected, _ := b.GenerateInDomain(1); id != expected {
+			t.Errorf("Unexpected id: %d, expected %d on %d times loop", id, expected, i)
+		}
+	}
+
+	// 测试换节点ID的克隆是否把节点ID放在域之上，不与原生成器的域重叠
+	d, err := b.CloneWithNodeID(21)
+	if err != nil {
+		t.Fatalf("failed to clone the generator: %v", err)
+	}
+	seen := make(map[int64]bool)
+	for domain := int64(0); domain < 4; domain++ {
+		for _, g := range []*Butterfly{b, d} {
+			id, err := g.GenerateInDomain(domain)
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			if seen[id] {
+				t.Errorf("duplicate id %d in domain %d", id, domain)
+			}
+			seen[id] = true
+		}
+	}
+	if nodeID, _, _ := ParseDomain(mustGenerate(t, d), 2); nodeID != 21 {
+		t.Errorf("Unexpected node ID: %d, expected %d", nodeID, 21)
+	}
+	for _, nodeID := range []int64{5, maxNodeID>>2 + 1} {
+		if _, err := b.CloneWithNodeID(nodeID); err == nil {
+			t.Errorf("node ID %d expects an error, but got nil", nodeID)
+		}
+	}
+}
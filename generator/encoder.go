@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CrockfordBase32 is the alphabet of Crockford's base32, which leaves out the ambiguous I, L, O and U
+const CrockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Encoder renders ids in a custom alphabet as fixed-width strings, so that encoded ids sort like the numeric ones
+type Encoder struct {
+	alphabet  []rune            // 字母表，按升序排列
+	digits    map[rune]int      // 字符对应的数值
+	width     int               // 编码后的固定长度
+	normalize func(r rune) rune // 解码前规范化字符
+}
+
+// NewEncoder constructs an encoder for an alphabet of at least 2 runes in strictly ascending order,
+// which rules out duplicates and keeps the lexical order of encoded ids equal to their numeric order
+func NewEncoder(alphabet string) (*Encoder, error) {
+	if !utf8.ValidString(alphabet) {
+		return nil, errors.New("invalid alphabet: not valid UTF-8")
+	}
+	runes := []rune(alphabet)
+	if len(runes) < 2 {
+		return nil, fmt.Errorf("invalid alphabet %q: at least 2 runes are required", alphabet)
+	}
+
+	e := &Encoder{alphabet: runes, digits: make(map[rune]int, len(runes))}
+	for i, r := range runes {
+		if _, ok := e.digits[r]; ok {
+			return nil, fmt.Errorf("invalid alphabet %q: the rune %q is duplicated", alphabet, r)
+		}
+		if i > 0 && r < runes[i-1] {
+			return nil, fmt.Errorf("invalid alphabet %q: the rune %q is out of ascending order", alphabet, r)
+		}
+		e.digits[r] = i
+	}
+	for value := uint64(math.MaxInt64); value > 0; value /= uint64(len(runes)) {
+		e.width++
+	}
+	return e, nil
+}
+
+// NewCrockfordEncoder constructs an encoder for CrockfordBase32, whose decoding ignores case and maps I, L to 1 and O to 0
+func NewCrockfordEncoder() *Encoder {
+	e, _ := NewEncoder(CrockfordBase32)
+	e.normalize = func(r rune) rune {
+		switch r = unicode.ToUpper(r); r {
+		case 'I', 'L':
+			return '1'
+		case 'O':
+			return '0'
+		default:
+			return r
+		}
+	}
+	return e
+}
+
+// Encode renders a non-negative id left-padded to the fixed width of the encoder,
+// a negative id does not fit into the width and is rejected
+func (e *Encoder) Encode(id int64) (string, error) {
+	if id < 0 {
+		return "", fmt.Errorf("invalid id %d: id must not be negative", id)
+	}
+	base := uint64(len(e.alphabet))
+	buf := make([]rune, e.width)
+	value := uint64(id)
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = e.alphabet[value%base]
+		value /= base
+	}
+	return string(buf), nil
+}
+
+// Decode is the inverse of Encode, it only accepts strings of the fixed width
+func (e *Encoder) Decode(s string) (int64, error) {
+	runes := []rune(s)
+	if len(runes) != e.width {
+		return 0, fmt.Errorf("failed to decode %q: expected %d runes, got %d", s, e.width, len(runes))
+	}
+
+	base := uint64(len(e.alphabet))
+	var value uint64
+	for i, r := range runes {
+		if e.normalize != nil {
+			r = e.normalize(r)
+		}
+		digit, ok := e.digits[r]
+		if !ok {
+			return 0, fmt.Errorf("failed to decode %q: invalid rune %q at %d", s, r, i)
+		}
+		if value > (math.MaxInt64-uint64(digit))/base {
+			return 0, fmt.Errorf("failed to decode %q: value overflows int64", s)
+		}
+		value = value*base + uint64(digit)
+	}
+	return int64(value), nil
+}
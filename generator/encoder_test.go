@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestEncoder(t *testing.T) {
+	e := NewCrockfordEncoder()
+	if s := mustEncode(t, e, 0); len(s) != 13 {
+		t.Errorf("Unexpected width: %d, expected %d", len(s), 13)
+	}
+
+	// 测试编码后解码是否得到原ID，且编码的字典序与数值顺序一致
+	b := NewButterfly(time.Now().UnixMilli())
+	ids := []int64{0, 1, 31, 32, math.MaxInt64}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, mustGenerate(t, b))
+	}
+	encoded := make([]string, 0, len(ids))
+	for _, id := range ids {
+		s := mustEncode(t, e, id)
+		decoded, err := e.Decode(s)
+		if err != nil {
+			t.Fatalf("failed to decode %q: %v", s, err)
+		}
+		if decoded != id {
+			t.Errorf("Unexpected decoded id: %d, expected %d", decoded, id)
+		}
+		encoded = append(encoded, s)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	sort.Strings(encoded)
+	for i := range ids {
+		if s := mustEncode(t, e, ids[i]); encoded[i] != s {
+			t.Errorf("Unexpected order: %q at %d, expected %q", encoded[i], i, s)
+		}
+	}
+
+	// 测试Crockford解码是否忽略大小写及歧义字符
+	if id, err := e.Decode("000000000000o"); err != nil || id != 0 {
+		t.Errorf("Unexpected decoding: %d, %v, expected 0", id, err)
+	}
+	if id, err := e.Decode("00000000000lI"); err != nil || id != 33 {
+		t.Errorf("Unexpected decoding: %d, %v, expected 33", id, err)
+	}
+
+	// 测试负数ID是否被拒绝而非截断
+	if s, err := e.Encode(-1); err == nil {
+		t.Errorf("negative id expects an error, but got %q", s)
+	}
+
+	// 测试非法输入与字母表是否被拒绝
+	for _, s := range []string{"", "0", "000000000000U", "ZZZZZZZZZZZZZ"} {
+		if _, err := e.Decode(s); err == nil {
+			t.Errorf("decoding %q expects an error, but got nil", s)
+		}
+	}
+	for _, alphabet := range []string{"", "a", "aa", "ba", "\xff\xfe"} {
+		if _, err := NewEncoder(alphabet); err == nil {
+			t.Errorf("alphabet %q expects an error, but got nil", alphabet)
+		}
+	}
+}
+
+func mustEncode(t *testing.T, e *Encoder, id int64) string {
+	t.Helper()
+	s, err := e.Encode(id)
+	if err != nil {
+		t.Fatalf("failed to encode %d: %v", id, err)
+	}
+	return s
+}
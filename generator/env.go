@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// The environment variables read by NewFromEnv
+const (
+	EnvNodeID           = "BUTTERFLY_NODE_ID"            // 节点ID，未设置时由主机地址哈希得出
+	EnvEpoch            = "BUTTERFLY_EPOCH"              // 纪元，未设置时为0
+	EnvTimestampBits    = "BUTTERFLY_TIMESTAMP_BITS"     // 时间戳位数
+	EnvHighSequenceBits = "BUTTERFLY_HIGH_SEQUENCE_BITS" // 高序列号位数
+	EnvNodeBits         = "BUTTERFLY_NODE_BITS"          // 节点ID位数
+	EnvLowSequenceBits  = "BUTTERFLY_LOW_SEQUENCE_BITS"  // 低序列号位数
+)
+
+// NewFromEnv constructs a clock-based generator configured by the Env* variables, for twelve-factor apps.
+// Unset bit counts fall back to DefaultConfig, an unset epoch to 0 and an unset node ID to one
+// derived like NewWithAutoMachine. Malformed or invalid values return an error.
+func NewFromEnv() (*Butterfly, error) {
+	cfg := DefaultConfig()
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{
+		{name: EnvTimestampBits, value: &cfg.TimestampBits},
+		{name: EnvHighSequenceBits, value: &cfg.HighSequenceBits},
+		{name: EnvNodeBits, value: &cfg.NodeBits},
+		{name: EnvLowSequenceBits, value: &cfg.LowSequenceBits},
+	} {
+		bits, err := lookupEnvInt(field.name, int64(*field.value))
+		if err != nil {
+			return nil, err
+		}
+		*field.value = int(bits)
+	}
+	l, err := cfg.layout()
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the generator from the environment: %w", err)
+	}
+
+	epoch, err := lookupEnvInt(EnvEpoch, 0)
+	if err != nil {
+		return nil, err
+	}
+	nodeID, err := lookupEnvInt(EnvNodeID, -1)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := os.LookupEnv(EnvNodeID); !ok {
+		key, err := hostKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive the node ID: %w", err)
+		}
+		nodeID = hashNodeID(key) & l.maxNodeID
+	}
+
+	b, err := New(WithClockMode(), WithConfig(cfg), WithEpoch(epoch), WithNodeID(nodeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the generator from the environment: %w", err)
+	}
+	return b, nil
+}
+
+// lookupEnvInt parses the environment variable name as a decimal integer, returning def if it is unset
+func lookupEnvInt(name string, def int64) (int64, error) {
+	text, ok := os.LookupEnv(name)
+	if !ok {
+		return def, nil
+	}
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, text, err)
+	}
+	return value, nil
+}
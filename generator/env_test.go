@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	// 测试合法的环境变量是否生效
+	epoch := time.Now().UnixMilli() - 1000
+	t.Setenv(EnvNodeID, "300")
+	t.Setenv(EnvEpoch, strconv.FormatInt(epoch, 10))
+	t.Setenv(EnvNodeBits, "10")
+	t.Setenv(EnvLowSequenceBits, "4")
+	b, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.NodeID() != 300 || b.Epoch() != epoch || b.layout.maxNodeID != 1<<10-1 || b.layout.maxLowSequence != 1<<4-1 {
+		t.Errorf("Unexpected generator: node ID %d, epoch %d, layout %+v", b.NodeID(), b.Epoch(), b.layout)
+	}
+
+	// 测试格式错误或非法的值是否返回错误
+	for name, value := range map[string]string{
+		EnvNodeID:           "1024",
+		EnvEpoch:            "yesterday",
+		EnvTimestampBits:    "41.5",
+		EnvHighSequenceBits: "9",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := NewFromEnv(); err == nil {
+				t.Errorf("%s=%s expects an error, but got nil", name, value)
+			}
+		})
+	}
+}
+
+func TestNewFromEnv_Defaults(t *testing.T) {
+	key, err := hostKey()
+	if err != nil {
+		t.Skipf("no suitable interface: %v", err)
+	}
+
+	// 测试未设置的环境变量是否回退到默认值
+	b, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.NodeID() != hashNodeID(key) || b.Epoch() != 0 || b.layout != defaultLayout {
+		t.Errorf("Unexpected generator: node ID %d, epoch %d, layout %+v", b.NodeID(), b.Epoch(), b.layout)
+	}
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWithEpoch(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	initTimestamp := time.Now().UnixMilli()
+	b, err := NewWithEpoch(initTimestamp, epoch)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试打包的时间戳是否减去了纪元
+	id := mustGenerate(t, b)
+	if packed := id >> timeShift; packed != initTimestamp-epoch {
+		t.Errorf("Unexpected packed timestamp: %d, expected %d", packed, initTimestamp-epoch)
+	}
+	timestamp, _, _, _, err := b.ParseID(id)
+	if err != nil {
+		t.Fatalf("failed to parse id %d: %v", id, err)
+	}
+	if timestamp != initTimestamp {
+		t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, initTimestamp)
+	}
+
+	if created := b.ExtractTime(id); created.UnixMilli() != initTimestamp {
+		t.Errorf("Unexpected creation time: %v, expected %v", created, time.UnixMilli(initTimestamp))
+	}
+
+	// 测试早于纪元的起始时间戳是否被拒绝
+	if _, err := NewWithEpoch(epoch-1, epoch); err == nil {
+		t.Error("timestamp earlier than the epoch expects an error, but got nil")
+	}
+}
+
+func TestWithEpochTime(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 测试纪元时间是否按布局的精度换算，且与配置选项的顺序无关
+	b, err := New(WithEpochTime(epoch), WithConfig(MicrosecondConfig()))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.Epoch() != epoch.UnixMicro() {
+		t.Errorf("Unexpected epoch: %d, expected %d", b.Epoch(), epoch.UnixMicro())
+	}
+	c, err := New(WithEpochTime(epoch))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if c.Epoch() != epoch.UnixMilli() {
+		t.Errorf("Unexpected epoch: %d, expected %d", c.Epoch(), epoch.UnixMilli())
+	}
+	id := mustGenerate(t, c)
+	if d := time.Since(c.ExtractTime(id)); d < 0 || d > time.Minute {
+		t.Errorf("Unexpected creation time: %v, expected about now", c.ExtractTime(id))
+	}
+
+	// 测试晚于起始时间戳的纪元与零值时间是否被拒绝
+	if _, err := New(WithEpochTime(epoch), WithStartTimestamp(epoch.UnixMilli()-1)); err == nil {
+		t.Error("epoch after the start timestamp expects an error, but got nil")
+	}
+	if _, err := New(WithEpochTime(time.Time{})); err == nil {
+		t.Error("zero epoch time expects an error, but got nil")
+	}
+}
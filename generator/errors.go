@@ -0,0 +1,21 @@
+package generator
+
+import "errors"
+
+var (
+	// ErrExhausted means the generator has no more ids for now, such as the sequences of a tick or the node IDs
+	// of an allocator, failing over to another generator may help
+	ErrExhausted = errors.New("no more id")
+	// ErrNodeIDOverflow means a node ID does not fit into the node bits, which is a configuration error not worth retrying
+	ErrNodeIDOverflow = errors.New("node ID overflow")
+	// ErrClosed means the generator was closed, generate with a new one instead
+	ErrClosed = errors.New("generator closed")
+	// ErrDuplicate means WithDuplicateCheck caught an id generated twice, which is a bug in the layout or the caller
+	ErrDuplicate = errors.New("duplicate id")
+	// ErrRateLimited means WithRateLimit refused an id, retrying later may succeed
+	ErrRateLimited = errors.New("rate limited")
+	// ErrTimestampOverflow means a timestamp does not fit into the timestamp bits, either as configured or after carrying
+	// the sequences of the max timestamp, i.e. the id space is used up, which every generating method reports alike
+	// and neither is worth retrying
+	ErrTimestampOverflow = errors.New("timestamp overflow")
+)
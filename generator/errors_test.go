@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	// 测试各类错误是否可以通过errors.Is区分
+	b := NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	if _, err := b.Generate(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+	if _, err := b.GenerateInBatches(1); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+
+	a := NewAtomicButterfly(maxTimestamp)
+	a.state = maxState
+	if _, err := a.Generate(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+	if _, err := a.GenerateInBatches(1); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+
+	if _, err := NewClockBased(maxNodeID + 1); !errors.Is(err, ErrNodeIDOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrNodeIDOverflow)
+	}
+	if _, err := New(WithStartTimestamp(maxTimestamp + 1)); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+
+	var now int64 = maxTimestamp
+	c, err := NewClockBasedWithClock(0, func() int64 { return now })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	now = maxTimestamp + 1
+	if _, err := c.GenerateWithClock(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+}
+
+func TestErrTimestampOverflow_MaxTimestamp(t *testing.T) {
+	// 测试在最大时间戳处单个与批量生成是否返回同一个错误
+	single := map[string]func() error{
+		"Generate": func() error {
+			_, err := exhaustedButterfly().Generate()
+			return err
+		},
+		"GenerateBytes": func() error {
+			_, err := exhaustedButterfly().GenerateBytes()
+			return err
+		},
+		"AtomicButterfly.Generate": func() error {
+			a := NewAtomicButterfly(maxTimestamp)
+			a.state = maxState
+			_, err := a.Generate()
+			return err
+		},
+	}
+	batch := map[string]func() error{
+		"GenerateInBatches": func() error {
+			_, err := exhaustedButterfly().GenerateInBatches(1)
+			return err
+		},
+		"GenerateBytesInBatches": func() error {
+			_, err := exhaustedButterfly().GenerateBytesInBatches(1)
+			return err
+		},
+		"Reserve": func() error {
+			_, _, err := exhaustedButterfly().Reserve(1)
+			return err
+		},
+		"AtomicButterfly.GenerateInBatches": func() error {
+			a := NewAtomicButterfly(maxTimestamp)
+			a.state = maxState
+			_, err := a.GenerateInBatches(1)
+			return err
+		},
+	}
+	for _, paths := range []map[string]func() error{single, batch} {
+		for name, generate := range paths {
+			if err := generate(); !errors.Is(err, ErrTimestampOverflow) || errors.Is(err, ErrExhausted) {
+				t.Errorf("Unexpected error of %s: %v, expected %v", name, err, ErrTimestampOverflow)
+			}
+		}
+	}
+}
+
+// exhaustedButterfly returns a generator whose last id is the max id
+func exhaustedButterfly() *Butterfly {
+	b := NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	return b
+}
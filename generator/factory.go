@@ -1,26 +1,143 @@
 package generator
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// unsetTimestamp marks that no option has set the start timestamp
+const unsetTimestamp = -1
+
+// maxFutureSkew is how many milliseconds the start timestamp of a clock-based generator may be ahead of its clock
+const maxFutureSkew = 1000
+
+// New constructs a generator from options, which are validated together once all of them are applied.
+// Without options it starts at the current time with the default layout, node ID 0 and epoch 0.
+func New(opts ...Option) (*Butterfly, error) {
+	b := &Butterfly{
+		timestamp: unsetTimestamp,
+		layout:    defaultLayout,
+		MaxSkew:   defaultMaxSkew,
+	}
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, err
+		}
+	}
+	if b.milliClock && b.layout.unit != time.Millisecond {
+		return nil, fmt.Errorf("the clock returns milliseconds, but the timestamp unit of the layout is %v", b.layout.unit)
+	}
+	if !b.epochTime.IsZero() {
+		b.epoch = b.layout.fromTime(b.epochTime)
+	}
+	b.RollbackThreshold = b.layout.ticks(defaultRollbackThreshold * time.Millisecond)
+	if b.timestamp == unsetTimestamp {
+		b.timestamp = b.now()
+	} else if b.clockBased {
+		if now := b.now(); b.timestamp > now+b.layout.ticks(maxFutureSkew*time.Millisecond) {
+			return nil, fmt.Errorf("the start timestamp %d is more than %dms ahead of the clock %d", b.timestamp, maxFutureSkew, now)
+		}
+	}
+
+	if b.overflowPolicy != PolicyAdvanceLogical && !b.clockBased {
+		return nil, fmt.Errorf("the overflow policy %d requires the clock mode", b.overflowPolicy)
+	}
+	if b.exclusive && !b.clockBased {
+		return nil, fmt.Errorf("the exclusive node ID %d requires the clock mode, as logical generators count through the node IDs", b.nodeID)
+	}
+	if b.domainBits > 0 {
+		if err := b.applyDomainBits(); err != nil {
+			return nil, err
+		}
+	}
+	if b.randomStart {
+		if err := b.randomizeSequences(); err != nil {
+			return nil, err
+		}
+		b.randomTick = b.clockBased
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	if b.exclusive {
+		if err := claimNodeID(b.nodeID); err != nil {
+			return nil, err
+		}
+		nodeID := b.nodeID
+		b.release = func() error {
+			releaseNodeID(nodeID)
+			return nil
+		}
+	}
+	return b, nil
+}
 
 func NewGeneratorWithNowTime() *Butterfly {
-	return NewButterfly(time.Now().UnixMilli())
+	return NewGeneratorWithClock(wallClock)
+}
+
+// NewGeneratorWithClock constructs a generator starting at the time read from clock, which must return milliseconds
+func NewGeneratorWithClock(clock func() int64) *Butterfly {
+	b := NewButterfly(clock())
+	b.clock = clock
+	return b
+}
+
+// NewDeterministic constructs a logical generator pinned to startTimestamp and nodeID, for replaying ids in tests.
+// As logical generators carry the low sequence into the node ID, nodeID is only the starting value of that counter
+// and not an identity: generators started at nearby node IDs emit the same ids after a few calls.
+// Neither the construction nor Generate and GenerateInBatches read the clock, so every generator constructed
+// with the same arguments emits the same ids in the same order.
+func NewDeterministic(startTimestamp, nodeID int64) (*Butterfly, error) {
+	return New(WithStartTimestamp(startTimestamp), WithNodeID(nodeID))
 }
 
 func NewButterfly(initTimestamp int64) *Butterfly {
-	return &Butterfly{timestamp: initTimestamp}
+	return &Butterfly{timestamp: initTimestamp, layout: defaultLayout}
+}
+
+// NewWithEpoch constructs a generator whose ids pack the timestamp as milliseconds since epoch.
+// Like Snowflake, a recent epoch such as 2020-01-01 keeps ids short for longer.
+func NewWithEpoch(initTimestamp, epoch int64) (*Butterfly, error) {
+	return New(WithStartTimestamp(initTimestamp), WithEpoch(epoch))
+}
+
+// NewWithConfig constructs a generator starting at the current time with the bit layout described by cfg
+func NewWithConfig(cfg Config) (*Butterfly, error) {
+	return New(WithConfig(cfg))
+}
+
+func NewButterflyList(timestamp int64) (*ButterflyList, error) {
+	return NewButterflyListWithGenerator(NewButterfly(timestamp))
 }
 
-func NewButterflyList(timestamp int64) *ButterflyList {
+// NewButterflyListWithGenerator constructs a list filled by the given generator
+func NewButterflyListWithGenerator(generator Generator) (*ButterflyList, error) {
 	var list = &ButterflyList{
-		generator:     NewButterfly(timestamp),
+		generator:     generator,
 		AtLeastCount:  200,
 		IncreaseCount: 3000,
 	}
-	list.construct()
+	if err := list.construct(); err != nil {
+		return nil, err
+	}
+	instanceMutex.Lock()
 	instanceList = append(instanceList, list)
-	return list
+	instanceMutex.Unlock()
+	return list, nil
+}
+
+func NewButterflyListWithNowTime() (*ButterflyList, error) {
+	return NewButterflyList(wallClock())
+}
+
+// NewClockBased constructs a generator for GenerateWithClock, the node ID must be unique among all generators
+func NewClockBased(nodeID int64) (*Butterfly, error) {
+	return New(WithClockMode(), WithNodeID(nodeID))
 }
 
-func NewButterflyListWithNowTime() *ButterflyList {
-	return NewButterflyList(time.Now().UnixMilli())
+// NewClockBasedWithClock is like NewClockBased but reads time from clock, which must return milliseconds
+func NewClockBasedWithClock(nodeID int64, clock func() int64) (*Butterfly, error) {
+	return New(WithClockMode(), WithNodeID(nodeID), WithClock(clock))
 }
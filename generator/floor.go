@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// floorInterval is how often a generator constructed by NewWithFloorFile persists its floor
+const floorInterval = time.Second
+
+// NewWithFloorFile constructs a clock-based generator that never goes below the timestamp floor persisted in path,
+// so ids do not regress across restarts even if the clock does. It starts at the later of the floor and the clock,
+// however far the floor is ahead, as that is what the floor is for. The floor is rewritten atomically
+// every floorInterval and on Close. Ids generated after the last write of a crashed process are not covered,
+// so keep the clock from rolling back by more than floorInterval.
+func NewWithFloorFile(path string, nodeID int64) (*Butterfly, error) {
+	floor, err := readFloor(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := New(WithClockMode(), WithNodeID(nodeID))
+	if err != nil {
+		return nil, err
+	}
+	// the floor bypasses the maxFutureSkew check of WithStartTimestamp, but not the range check of Validate
+	if floor > b.timestamp {
+		b.timestamp = floor
+		if err := b.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to start above the floor %d of %s: %w", floor, path, err)
+		}
+	}
+	if err := writeFloor(path, b.Timestamp()+1); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(floorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := writeFloor(path, b.Timestamp()+1); err != nil {
+					b.mutex.Lock()
+					b.logf("%v", err)
+					b.mutex.Unlock()
+				}
+			}
+		}
+	}()
+
+	b.release = func() error {
+		close(done)
+		<-stopped
+		return writeFloor(path, b.Timestamp()+1)
+	}
+	return b, nil
+}
+
+// readFloor reads the floor persisted in path, or 0 if the file does not exist
+func readFloor(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the floor: %w", err)
+	}
+	floor, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the floor of %s: %w", path, err)
+	}
+	return floor, nil
+}
+
+// writeFloor replaces the floor in path atomically by renaming a synced temporary file over it
+func writeFloor(path string, floor int64) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write the floor: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(strconv.FormatInt(floor, 10) + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write the floor: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write the floor: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write the floor: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write the floor: %w", err)
+	}
+	return nil
+}
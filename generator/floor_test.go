@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNewWithFloorFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "floor")
+
+	// 测试没有地板文件时是否从当前时间开始
+	b, err := NewWithFloorFile(path, 1)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("failed to close the generator: %v", err)
+	}
+	floor, err := readFloor(path)
+	if err != nil {
+		t.Fatalf("failed to read the floor: %v", err)
+	}
+	if floor <= Decompose(id).Timestamp {
+		t.Errorf("Unexpected floor: %d, expected it above %d", floor, Decompose(id).Timestamp)
+	}
+
+	// 测试地板在时钟之前时是否从地板开始
+	ahead := time.Now().UnixMilli() + 500
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(ahead, 10)), 0o644); err != nil {
+		t.Fatalf("failed to write the floor: %v", err)
+	}
+	b, err = NewWithFloorFile(path, 1)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	defer b.Close()
+	next, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if timestamp := Decompose(next).Timestamp; timestamp < ahead {
+		t.Errorf("Unexpected timestamp: %d, expected at least %d", timestamp, ahead)
+	}
+
+	// 测试远超时钟的地板是否仍从地板开始
+	far := time.Now().UnixMilli() + time.Hour.Milliseconds()
+	farPath := filepath.Join(t.TempDir(), "floor")
+	if err := os.WriteFile(farPath, []byte(strconv.FormatInt(far, 10)), 0o644); err != nil {
+		t.Fatalf("failed to write the floor: %v", err)
+	}
+	c, err := NewWithFloorFile(farPath, 1)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	defer c.Close()
+	id, err = c.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if timestamp := Decompose(id).Timestamp; timestamp < far {
+		t.Errorf("Unexpected timestamp: %d, expected at least %d", timestamp, far)
+	}
+
+	// 测试格式错误或超出范围的地板是否返回错误
+	for _, content := range []string{"yesterday", strconv.FormatInt(maxTimestamp+1, 10)} {
+		bad := filepath.Join(t.TempDir(), "floor")
+		if err := os.WriteFile(bad, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write the floor: %v", err)
+		}
+		if _, err := NewWithFloorFile(bad, 1); err == nil {
+			t.Errorf("floor %q expects an error, but got nil", content)
+		}
+	}
+}
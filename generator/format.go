@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Pad formats an id as a 19 digit zero-padded decimal, so that lexical order matches numeric order.
+// The max int64 has 19 digits, so padding never truncates a generated id.
+func Pad(id int64) string {
+	return fmt.Sprintf("%019d", id)
+}
+
+// GeneratePadded generates an id and formats it with Pad
+func (b *Butterfly) GeneratePadded() (string, error) {
+	id, err := b.Generate()
+	if err != nil {
+		return "", err
+	}
+	return Pad(id), nil
+}
+
+// describeTimeLayout formats creation times in Describe, with milliseconds and always in UTC
+const describeTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Describe formats the fields of an id of the default layout and epoch for logs, in packing order:
+// "ts=1714521600000 (2024-05-01T00:00:00.000Z) high=3 node=12 low=1"
+func Describe(id int64) string {
+	return defaultLayout.describe(id, 0)
+}
+
+// Describe is like the package level Describe but honors the layout and epoch of the generator
+func (b *Butterfly) Describe(id int64) string {
+	return b.layout.describe(id, b.epoch)
+}
+
+func (l layout) describe(id, epoch int64) string {
+	timestamp, highSequence, nodeID, lowSequence, err := l.parse(id, epoch)
+	if err != nil {
+		return err.Error()
+	}
+
+	buf := make([]byte, 0, 96)
+	buf = append(buf, "ts="...)
+	buf = strconv.AppendInt(buf, timestamp, 10)
+	buf = append(buf, " ("...)
+	buf = l.toTime(timestamp).UTC().AppendFormat(buf, describeTimeLayout)
+	buf = append(buf, ") high="...)
+	buf = strconv.AppendInt(buf, highSequence, 10)
+	buf = append(buf, " node="...)
+	buf = strconv.AppendInt(buf, nodeID, 10)
+	buf = append(buf, " low="...)
+	buf = strconv.AppendInt(buf, lowSequence, 10)
+	return string(buf)
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPad(t *testing.T) {
+	// 测试最大值是否恰好19位
+	if s := Pad(math.MaxInt64); s != strconv.FormatInt(math.MaxInt64, 10) || len(s) != 19 {
+		t.Errorf("Unexpected padding of the max id: %q", s)
+	}
+	if s := Pad(42); s != "0000000000000000042" {
+		t.Errorf("Unexpected padding: %q, expected %q", s, "0000000000000000042")
+	}
+
+	// 测试补零后的字典序是否与数值顺序一致
+	b := NewButterfly(time.Now().UnixMilli())
+	ids := []int64{0, 1, 9, 10, math.MaxInt64}
+	for i := 0; i < 100; i++ {
+		s, err := b.GeneratePadded()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		id, _ := strconv.ParseInt(s, 10, 64)
+		ids = append(ids, id)
+	}
+	padded := make([]string, 0, len(ids))
+	for _, id := range ids {
+		padded = append(padded, Pad(id))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	sort.Strings(padded)
+	for i := range ids {
+		if padded[i] != Pad(ids[i]) {
+			t.Errorf("Unexpected order: %q at %d, expected %q", padded[i], i, Pad(ids[i]))
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	b := NewButterfly(1714521600000)
+	b.highSequence = 3
+	b.nodeID = 12
+
+	// 测试描述是否按打包顺序列出各字段
+	id := mustGenerate(t, b)
+	expected := "ts=1714521600000 (2024-05-01T00:00:00.000Z) high=3 node=12 low=1"
+	if s := Describe(id); s != expected {
+		t.Errorf("Unexpected description: %q, expected %q", s, expected)
+	}
+	if s := b.Describe(id); s != expected {
+		t.Errorf("Unexpected description: %q, expected %q", s, expected)
+	}
+	if s := Describe(-1); s == "" {
+		t.Error("negative id expects a description of the error, but got an empty string")
+	}
+}
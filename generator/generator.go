@@ -0,0 +1,13 @@
+package generator
+
+// Generator is implemented by everything that generates ids, callers should depend on it rather than a concrete type
+type Generator interface {
+	Generate() (int64, error)
+	GenerateInBatches(count int) ([]int64, error)
+}
+
+var (
+	_ Generator = (*Butterfly)(nil)
+	_ Generator = (*AtomicButterfly)(nil)
+	_ Generator = (*Pool)(nil)
+)
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// defaultMaxSkew is how far the timestamp of a clock-based generator may run ahead of its clock before Healthy fails
+const defaultMaxSkew = time.Second
+
+// Healthy reports whether the generator can still emit an id without consuming one, e.g. for readiness probes.
+// It fails with ErrTimestampOverflow when the generator has reached the max id, when its node ID is out of range, or in clock mode
+// when the timestamp runs more than MaxSkew ahead of the clock.
+func (b *Butterfly) Healthy() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.remaining() <= 0 {
+		return fmt.Errorf("%w: the generator has used up its ids", ErrTimestampOverflow)
+	}
+	if err := b.layout.validateNodeID(b.nodeID); err != nil {
+		return err
+	}
+	if b.clockBased && b.MaxSkew > 0 {
+		if skew := time.Duration(b.timestamp-b.now()) * b.layout.unit; skew > b.MaxSkew {
+			return fmt.Errorf("the timestamp is %v ahead of the clock, exceeding the max skew %v", skew, b.MaxSkew)
+		}
+	}
+	return nil
+}
+
+// TimeToExhaustion forecasts how long Generate can sustain ratePerSecond ids per second before it is exhausted,
+// computed from Remaining. It returns the max duration if the rate is not positive or the forecast exceeds it.
+func (b *Butterfly) TimeToExhaustion(ratePerSecond int64) time.Duration {
+	if ratePerSecond <= 0 {
+		return math.MaxInt64
+	}
+	remaining := b.Remaining()
+	seconds := remaining / ratePerSecond
+	if seconds > math.MaxInt64/int64(time.Second)-1 {
+		return math.MaxInt64
+	}
+	// the remainder times a second may exceed 64 bits for rates above about 9.2e9, so multiply in 128 bits
+	hi, lo := bits.Mul64(uint64(remaining%ratePerSecond), uint64(time.Second))
+	nanos, _ := bits.Div64(hi, lo, uint64(ratePerSecond))
+	return time.Duration(seconds)*time.Second + time.Duration(nanos)
+}
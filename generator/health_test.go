@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestButterfly_Healthy(t *testing.T) {
+	var now int64 = 1000
+	b, err := NewClockBasedWithClock(1, func() int64 { return now })
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if err := b.Healthy(); err != nil {
+		t.Errorf("fresh generator expects no error, but got %v", err)
+	}
+	last := b.compose()
+	if err := b.Healthy(); err != nil || b.compose() != last {
+		t.Errorf("Healthy expects not to consume an id, but got %v and %d", err, b.compose())
+	}
+
+	// 测试时间戳超前时钟过多时是否不健康
+	b.timestamp = now + 1001
+	if err := b.Healthy(); err == nil {
+		t.Error("skewed generator expects an error, but got nil")
+	}
+	b.MaxSkew = 2 * time.Second
+	if err := b.Healthy(); err != nil {
+		t.Errorf("skew within the bound expects no error, but got %v", err)
+	}
+
+	// 测试耗尽的生成器是否不健康
+	c := NewButterfly(maxTimestamp)
+	c.highSequence = maxHighSequence
+	c.nodeID = maxNodeID
+	c.lowSequence = maxLowSequence
+	if err := c.Healthy(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+}
+
+func TestButterfly_TimeToExhaustion(t *testing.T) {
+	b := NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+
+	// 测试剩余ID数与速率换算出的时长
+	remaining := b.Remaining()
+	if d := b.TimeToExhaustion(remaining); d != time.Second {
+		t.Errorf("Unexpected time to exhaustion: %v, expected %v", d, time.Second)
+	}
+	if d := b.TimeToExhaustion(2 * remaining); d != 500*time.Millisecond {
+		t.Errorf("Unexpected time to exhaustion: %v, expected %v", d, 500*time.Millisecond)
+	}
+
+	// 测试极高速率下不足一秒的部分是否溢出
+	c := NewButterfly(0)
+	rate := int64(1e12)
+	expected := new(big.Int).Mul(big.NewInt(c.Remaining()), big.NewInt(int64(time.Second)))
+	expected.Quo(expected, big.NewInt(rate))
+	if d := c.TimeToExhaustion(rate); int64(d) != expected.Int64() {
+		t.Errorf("Unexpected time to exhaustion: %v, expected %v", d, time.Duration(expected.Int64()))
+	}
+
+	// 测试速率为0或时长溢出时是否返回最大时长
+	if d := b.TimeToExhaustion(0); d != math.MaxInt64 {
+		t.Errorf("Unexpected time to exhaustion: %v, expected %v", d, time.Duration(math.MaxInt64))
+	}
+	if d := NewButterfly(0).TimeToExhaustion(1); d != math.MaxInt64 {
+		t.Errorf("Unexpected time to exhaustion: %v, expected %v", d, time.Duration(math.MaxInt64))
+	}
+}
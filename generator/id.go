@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"strconv"
+)
+
+// ID is a generated id that survives JSON round-trips through clients whose numbers lose precision above 2^53
+type ID int64
+
+// GenerateID generates an id as an ID
+func (b *Butterfly) GenerateID() (ID, error) {
+	id, err := b.Generate()
+	return ID(id), err
+}
+
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON emits the id as a quoted decimal string
+func (id ID) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(id.String())), nil
+}
+
+// UnmarshalJSON accepts both a quoted decimal string and a raw number
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(text)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal id %s: %w", data, err)
+		}
+		text = unquoted
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal id %s: %w", data, err)
+	}
+	*id = ID(value)
+	return nil
+}
+
+// MarshalText emits the id as a decimal string for text based encoders such as YAML or XML
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText accepts the decimal string emitted by MarshalText
+func (id *ID) UnmarshalText(text []byte) error {
+	value, err := strconv.ParseInt(string(text), 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal id %q: %w", text, err)
+	}
+	*id = ID(value)
+	return nil
+}
+
+// The registration lets encoding/gob carry an ID in an interface field, concrete fields only need MarshalBinary
+func init() {
+	gob.Register(ID(0))
+}
+
+// MarshalBinary emits the id as 8 big-endian bytes, the byte order of the Reader, which encoding/gob uses as well
+func (id ID) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(id))
+	return data, nil
+}
+
+// UnmarshalBinary accepts the 8 big-endian bytes emitted by MarshalBinary
+func (id *ID) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return fmt.Errorf("failed to unmarshal id: expected 8 bytes, got %d", len(data))
+	}
+	*id = ID(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+// Value stores the id as an int64, e.g. into a BIGINT column
+func (id ID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Scan accepts the int64, []byte and string forms drivers return for integer columns, NULL is rejected
+func (id *ID) Scan(src any) error {
+	var text string
+	switch value := src.(type) {
+	case int64:
+		*id = ID(value)
+		return nil
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	default:
+		return fmt.Errorf("failed to scan id: unsupported type %T", src)
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to scan id %q: %w", text, err)
+	}
+	*id = ID(value)
+	return nil
+}
+
+// GenerateBytes generates an id encoded like MarshalBinary, for keys of byte-ordered stores such as BoltDB.
+// As ids are never negative, the byte order of the keys matches the numeric order of the ids.
+func (b *Butterfly) GenerateBytes() ([]byte, error) {
+	id, err := b.Generate()
+	if err != nil {
+		return nil, err
+	}
+	return ID(id).MarshalBinary()
+}
+
+// GenerateBytesInBatches is like GenerateInBatches but encodes the ids like GenerateBytes, sharing one backing array
+func (b *Butterfly) GenerateBytesInBatches(count int) ([][]byte, error) {
+	idList, err := b.GenerateInBatches(count)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 8*len(idList))
+	keys := make([][]byte, len(idList))
+	for i, id := range idList {
+		keys[i] = buf[8*i : 8*i+8 : 8*i+8]
+		binary.BigEndian.PutUint64(keys[i], uint64(id))
+	}
+	return keys, nil
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestID_JSON(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	id, err := b.GenerateID()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+
+	// 测试序列化后反序列化是否得到原ID
+	type payload struct {
+		ID ID `json:"id"`
+	}
+	data, err := json.Marshal(payload{ID: id})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if expected := `{"id":"` + id.String() + `"}`; string(data) != expected {
+		t.Errorf("Unexpected json: %s, expected %s", data, expected)
+	}
+	var decoded payload
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", data, err)
+	}
+	if decoded.ID != id {
+		t.Errorf("Unexpected id: %d, expected %d", decoded.ID, id)
+	}
+
+	// 测试是否兼容数字形式
+	if err := json.Unmarshal([]byte(`{"id":7340033296673423361}`), &decoded); err != nil {
+		t.Fatalf("failed to unmarshal a raw number: %v", err)
+	}
+	if decoded.ID != 7340033296673423361 {
+		t.Errorf("Unexpected id: %d, expected %d", decoded.ID, int64(7340033296673423361))
+	}
+
+	// 测试非数字字符串是否被拒绝
+	for _, data := range []string{`{"id":"abc"}`, `{"id":"12a"}`, `{"id":""}`, `{"id":1.5}`} {
+		if err := json.Unmarshal([]byte(data), &decoded); err == nil {
+			t.Errorf("unmarshaling %s expects an error, but got nil", data)
+		}
+	}
+}
+
+func TestID_SQL(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	id, err := b.GenerateID()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+
+	// 测试写入数据库的值是否为int64
+	value, err := id.Value()
+	if err != nil {
+		t.Fatalf("failed to get the value of id %d: %v", id, err)
+	}
+	if v, ok := value.(int64); !ok || v != int64(id) {
+		t.Errorf("Unexpected value: %#v, expected int64 %d", value, int64(id))
+	}
+
+	// 测试不同驱动返回的各类值是否都能被扫描
+	for _, src := range []any{int64(id), []byte(id.String()), id.String()} {
+		var scanned ID
+		if err := scanned.Scan(src); err != nil {
+			t.Fatalf("failed to scan %#v: %v", src, err)
+		}
+		if scanned != id {
+			t.Errorf("Unexpected scanned id: %d, expected %d", scanned, id)
+		}
+	}
+
+	// 测试非法的值是否被拒绝
+	for _, src := range []any{nil, 1.5, []byte("abc"), "9223372036854775808"} {
+		var scanned ID
+		if err := scanned.Scan(src); err == nil {
+			t.Errorf("scanning %#v expects an error, but got nil", src)
+		}
+	}
+}
+
+func TestID_Text(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	id, err := b.GenerateID()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+
+	// 测试文本编码器序列化后反序列化是否得到原ID
+	type document struct {
+		ID     ID `xml:"id"`
+		Parent ID `xml:"parent,attr"`
+	}
+	data, err := xml.Marshal(document{ID: id, Parent: id - 1})
+	if err != nil {
+		t.Fatalf("failed to marshal id: %v", err)
+	}
+	var decoded document
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", data, err)
+	}
+	if decoded.ID != id || decoded.Parent != id-1 {
+		t.Errorf("Unexpected decoded ids: %+v, expected %d and %d", decoded, id, id-1)
+	}
+
+	// 测试作为JSON对象的键是否同样可用
+	data, err = json.Marshal(map[ID]bool{id: true})
+	if err != nil {
+		t.Fatalf("failed to marshal id: %v", err)
+	}
+	var keys map[ID]bool
+	if err := json.Unmarshal(data, &keys); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", data, err)
+	}
+	if !keys[id] {
+		t.Errorf("Unexpected keys: %v, expected %d", keys, id)
+	}
+
+	// 测试非法的文本是否被拒绝
+	var invalid ID
+	if err := invalid.UnmarshalText([]byte("abc")); err == nil {
+		t.Error("invalid text expects an error, but got nil")
+	}
+}
+
+func TestID_Binary(t *testing.T) {
+	// 测试字节序是否为大端，即最高字节在前
+	id := ID(0x0102030405060708)
+	data, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal id: %v", err)
+	}
+	expected := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Unexpected bytes: %v, expected %v", data, expected)
+	}
+
+	var decoded ID
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("failed to unmarshal %v: %v", data, err)
+	}
+	if decoded != id {
+		t.Errorf("Unexpected decoded id: %d, expected %d", decoded, id)
+	}
+	if err := decoded.UnmarshalBinary(data[:7]); err == nil {
+		t.Error("short data expects an error, but got nil")
+	}
+}
+
+func TestID_Gob(t *testing.T) {
+	type order struct {
+		ID     ID
+		Parent *ID
+		Items  []ID
+		Ref    any
+	}
+	b := NewButterfly(time.Now().UnixMilli())
+	parent := ID(mustGenerate(t, b))
+	sent := order{ID: ID(mustGenerate(t, b)), Parent: &parent, Items: []ID{0, ID(mustGenerate(t, b))}, Ref: ID(mustGenerate(t, b))}
+
+	// 测试包含ID的结构体是否经gob往返后保持不变
+	var wire bytes.Buffer
+	if err := gob.NewEncoder(&wire).Encode(sent); err != nil {
+		t.Fatalf("failed to encode %+v: %v", sent, err)
+	}
+	var received order
+	if err := gob.NewDecoder(&wire).Decode(&received); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if received.ID != sent.ID || *received.Parent != parent || received.Ref != sent.Ref {
+		t.Errorf("Unexpected order: %+v, expected %+v", received, sent)
+	}
+	if len(received.Items) != len(sent.Items) || received.Items[0] != sent.Items[0] || received.Items[1] != sent.Items[1] {
+		t.Errorf("Unexpected items: %v, expected %v", received.Items, sent.Items)
+	}
+}
+
+func TestButterfly_GenerateBytes(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	first, err := b.GenerateBytes()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	keys, err := b.GenerateBytesInBatches(1000)
+	if err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	keys = append([][]byte{first}, keys...)
+
+	// 测试字节序与数值顺序是否一致
+	for i := 1; i < len(keys); i++ {
+		if bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			t.Errorf("key not incrementing: %v, %v on %d times loop", keys[i], keys[i-1], i)
+		}
+		var prev, id ID
+		if err := prev.UnmarshalBinary(keys[i-1]); err != nil {
+			t.Fatalf("failed to unmarshal %v: %v", keys[i-1], err)
+		}
+		if err := id.UnmarshalBinary(keys[i]); err != nil {
+			t.Fatalf("failed to unmarshal %v: %v", keys[i], err)
+		}
+		if id <= prev {
+			t.Errorf("ID not incrementing: %d, %d on %d times loop", id, prev, i)
+		}
+	}
+
+	// 测试批量生成的键互不影响
+	keys[1] = append(keys[1], 0)
+	if len(keys[2]) != 8 || bytes.Compare(keys[1][:8], keys[2]) >= 0 {
+		t.Errorf("Unexpected key after appending to its neighbour: %v", keys[2])
+	}
+}
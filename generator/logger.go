@@ -0,0 +1,29 @@
+package generator
+
+// Logger receives diagnostics of the generator, e.g. to route them into zap or zerolog without the package depending on them.
+// Printf runs while the generator is locked, so it must not call back into it.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// WithLogger registers a logger on the generator, without one diagnostics are dropped
+func WithLogger(logger Logger) Option {
+	return func(b *Butterfly) error {
+		b.logger = logger
+		return nil
+	}
+}
+
+// SetLogger registers a logger on the generator, nil drops diagnostics again
+func (b *Butterfly) SetLogger(logger Logger) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.logger = logger
+}
+
+// logf sends a diagnostic to the logger if any, the caller must hold the mutex
+func (b *Butterfly) logf(format string, args ...any) {
+	if b.logger != nil {
+		b.logger.Printf(format, args...)
+	}
+}
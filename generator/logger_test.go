@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingLogger records the lines it receives
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...any) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestButterfly_Logger(t *testing.T) {
+	logger := &recordingLogger{}
+	readings := []int64{1000, 1000, 998, 1000}
+	b, err := New(WithClockMode(), WithLogger(logger), WithClock(func() int64 {
+		now := readings[0]
+		readings = readings[1:]
+		return now
+	}))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试时钟回拨是否被记录
+	if _, err := b.GenerateWithClock(); err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if _, err := b.GenerateWithClock(); err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "backwards by 2ms") {
+		t.Errorf("Unexpected lines: %q, expected one line about the rollback", logger.lines)
+	}
+
+	// 测试移除日志后是否不再记录
+	b.SetLogger(nil)
+	b.highSequence = maxHighSequence
+	b.lowSequence = maxLowSequence
+	readings = []int64{1000}
+	if _, err := b.GenerateWithClock(); err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if len(logger.lines) != 1 {
+		t.Errorf("Unexpected lines: %q, expected no more lines", logger.lines)
+	}
+}
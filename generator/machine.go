@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strings"
+)
+
+// NewWithAutoMachine constructs a clock-based generator whose node ID is derived by hashing
+// the first non-loopback IPv4 address of the host, or its MAC address if no such address exists.
+// Different hosts may hash to the same node ID, check NodeID across the fleet to detect clashes.
+func NewWithAutoMachine() (*Butterfly, error) {
+	key, err := hostKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the node ID: %w", err)
+	}
+	return NewClockBased(hashNodeID(key))
+}
+
+// NewFromMAC constructs a clock-based generator whose node ID is derived by hashing the MAC address
+// of the first up, non-loopback and non-virtual interface, for hosts with stable MACs but changing IPs.
+// Different hosts may hash to the same node ID, check NodeID across the fleet to detect clashes.
+func NewFromMAC() (*Butterfly, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the node ID: %w", err)
+	}
+	mac, err := physicalMAC(interfaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the node ID: %w", err)
+	}
+	return NewClockBased(hashNodeID(mac))
+}
+
+// NewFromMachineKey constructs a clock-based generator whose node ID is HashMachineKey(key),
+// for configs naming machines by strings such as "dc1-worker7"
+func NewFromMachineKey(key string) (*Butterfly, error) {
+	if key == "" {
+		return nil, errors.New("failed to derive the node ID: the machine key is empty")
+	}
+	return NewClockBased(HashMachineKey(key))
+}
+
+// HashMachineKey maps key to a node ID with FNV-1a, which is stable across runs, platforms and versions.
+// With only 8192 node IDs collisions come early: 100 keys collide with a chance of about 45%,
+// so check the node IDs of all keys up front or assign them explicitly.
+func HashMachineKey(key string) int64 {
+	return hashNodeID([]byte(key))
+}
+
+// virtualInterfacePrefixes are the name prefixes of interfaces created by container and VM runtimes
+var virtualInterfacePrefixes = []string{"docker", "veth", "br-", "virbr", "vmnet", "vboxnet", "cni", "flannel", "cali", "tun", "tap"}
+
+// physicalMAC returns the MAC address of the first up, non-loopback interface that does not look virtual
+func physicalMAC(interfaces []net.Interface) (net.HardwareAddr, error) {
+	for _, i := range interfaces {
+		if i.Flags&net.FlagUp == 0 || i.Flags&net.FlagLoopback != 0 || len(i.HardwareAddr) == 0 || isVirtualInterface(i.Name) {
+			continue
+		}
+		return i.HardwareAddr, nil
+	}
+	return nil, errors.New("no up, non-loopback and physical interface with a MAC address is found")
+}
+
+func isVirtualInterface(name string) bool {
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostKey returns the first non-loopback IPv4 address of an up interface, falling back to the first MAC address
+func hostKey() ([]byte, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var mac net.HardwareAddr
+	for _, i := range interfaces {
+		if i.Flags&net.FlagUp == 0 || i.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		if mac == nil && len(i.HardwareAddr) > 0 {
+			mac = i.HardwareAddr
+		}
+
+		addrs, err := i.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+				if ip := ipNet.IP.To4(); ip != nil {
+					return ip, nil
+				}
+			}
+		}
+	}
+	if mac != nil {
+		return mac, nil
+	}
+	return nil, errors.New("no up and non-loopback interface with an IPv4 or MAC address is found")
+}
+
+// hashNodeID hashes key into [0, maxNodeID] with FNV-1a
+func hashNodeID(key []byte) int64 {
+	h := fnv.New64a()
+	h.Write(key)
+	return int64(h.Sum64() % (maxNodeID + 1))
+}
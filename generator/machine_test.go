@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"net"
+	"testing"
+)
+
+func TestHashNodeID(t *testing.T) {
+	// 测试哈希是否稳定且在节点ID范围内
+	for _, key := range []string{"", "10.0.0.1", "192.168.1.20", "00:1a:2b:3c:4d:5e"} {
+		nodeID := hashNodeID([]byte(key))
+		if nodeID < 0 || nodeID > maxNodeID {
+			t.Errorf("node ID %d of %q is out of range [0, %d]", nodeID, key, maxNodeID)
+		}
+		if again := hashNodeID([]byte(key)); again != nodeID {
+			t.Errorf("Unexpected node ID: %d, expected %d for %q", again, nodeID, key)
+		}
+	}
+}
+
+func TestNewWithAutoMachine(t *testing.T) {
+	key, err := hostKey()
+	if err != nil {
+		t.Skipf("no suitable interface: %v", err)
+	}
+
+	b, err := NewWithAutoMachine()
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.NodeID() != hashNodeID(key) {
+		t.Errorf("Unexpected node ID: %d, expected %d", b.NodeID(), hashNodeID(key))
+	}
+}
+
+func TestPhysicalMAC(t *testing.T) {
+	mac := net.HardwareAddr{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}
+	interfaces := []net.Interface{
+		{Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+		{Name: "docker0", Flags: net.FlagUp, HardwareAddr: net.HardwareAddr{0x02, 0x42, 0, 0, 0, 1}},
+		{Name: "veth1234", Flags: net.FlagUp, HardwareAddr: net.HardwareAddr{0x02, 0x42, 0, 0, 0, 2}},
+		{Name: "eth1", HardwareAddr: net.HardwareAddr{0x02, 0x42, 0, 0, 0, 3}},
+		{Name: "eth0", Flags: net.FlagUp, HardwareAddr: mac},
+	}
+
+	// 测试是否跳过回环、虚拟及未启用的网卡
+	found, err := physicalMAC(interfaces)
+	if err != nil {
+		t.Fatalf("failed to find the MAC address: %v", err)
+	}
+	if found.String() != mac.String() {
+		t.Errorf("Unexpected MAC address: %s, expected %s", found, mac)
+	}
+
+	if _, err := physicalMAC(interfaces[:4]); err == nil {
+		t.Error("no physical interface expects an error, but got nil")
+	}
+}
+
+func TestNewFromMachineKey(t *testing.T) {
+	// 测试哈希结果是否跨运行稳定
+	if nodeID := HashMachineKey("dc1-worker7"); nodeID != HashMachineKey("dc1-worker7") || nodeID != hashNodeID([]byte("dc1-worker7")) {
+		t.Errorf("Unexpected node ID: %d, expected a stable hash", nodeID)
+	}
+	// FNV-1a的64位哈希值是固定的，取模后也应固定
+	if nodeID := HashMachineKey("a"); nodeID != int64(0xaf63dc4c8601ec8c%(maxNodeID+1)) {
+		t.Errorf("Unexpected node ID: %d, expected %d", nodeID, int64(0xaf63dc4c8601ec8c%(maxNodeID+1)))
+	}
+
+	b, err := NewFromMachineKey("dc1-worker7")
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.NodeID() != HashMachineKey("dc1-worker7") {
+		t.Errorf("Unexpected node ID: %d, expected %d", b.NodeID(), HashMachineKey("dc1-worker7"))
+	}
+	if _, err := NewFromMachineKey(""); err == nil {
+		t.Error("empty key expects an error, but got nil")
+	}
+}
@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNoCopy(t *testing.T) {
+	// 测试各生成器是否包含copylocks检查识别的锁字段
+	locker := reflect.TypeOf((*sync.Locker)(nil)).Elem()
+	for _, value := range []any{Butterfly{}, AtomicButterfly{}, Pool{}, ButterflyList{}} {
+		typ := reflect.TypeOf(value)
+		found := false
+		for i := 0; i < typ.NumField(); i++ {
+			if reflect.PointerTo(typ.Field(i).Type).Implements(locker) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s expects a field implementing sync.Locker, but got none", typ.Name())
+		}
+	}
+}
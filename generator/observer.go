@@ -0,0 +1,58 @@
+package generator
+
+import "fmt"
+
+// Observer receives generation events, e.g. to feed metrics without the package depending on a metrics library.
+// The callbacks run while the generator is locked, so they must be fast and must not call back into it.
+type Observer interface {
+	OnGenerate(id int64) // 生成了一个ID
+	OnOverflow()         // 序列号用尽，时间戳被逻辑推进
+	OnError(err error)   // 生成失败
+}
+
+// WithObserver registers an observer on the generator
+func WithObserver(observer Observer) Option {
+	return func(b *Butterfly) error {
+		b.observer = observer
+		return nil
+	}
+}
+
+// SetObserver registers an observer on the generator, nil removes it
+func (b *Butterfly) SetObserver(observer Observer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.observer = observer
+}
+
+// observe counts and reports the result of a generation to the observer, the caller must hold the mutex.
+// As a backstop to throttle, it also refuses the ids of a generator closed while a generation was under way.
+func (b *Butterfly) observe(id int64, err error) (int64, error) {
+	if err == nil && b.closed {
+		id, err = 0, ErrClosed
+	}
+	if err == nil && b.recent != nil && !b.recent.add(id) {
+		id, err = 0, fmt.Errorf("%w: the id %d was generated before", ErrDuplicate, id)
+	}
+	if err != nil {
+		b.stats.Errors++
+	} else {
+		b.stats.Generated++
+	}
+	if b.observer != nil {
+		if err != nil {
+			b.observer.OnError(err)
+		} else {
+			b.observer.OnGenerate(id)
+		}
+	}
+	return id, err
+}
+
+// observeOverflow counts and reports a logical timestamp advance to the observer, the caller must hold the mutex
+func (b *Butterfly) observeOverflow() {
+	b.stats.Overflows++
+	if b.observer != nil {
+		b.observer.OnOverflow()
+	}
+}
@@ -0,0 +1,48 @@
+package generator
+
+import "testing"
+
+// countingObserver counts the events it receives
+type countingObserver struct {
+	generated int
+	overflows int
+	errors    int
+}
+
+func (o *countingObserver) OnGenerate(int64) { o.generated++ }
+func (o *countingObserver) OnOverflow()      { o.overflows++ }
+func (o *countingObserver) OnError(error)    { o.errors++ }
+
+func TestButterfly_Observer(t *testing.T) {
+	observer := &countingObserver{}
+	b, err := New(WithStartTimestamp(maxTimestamp-1), WithObserver(observer))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+
+	// 测试各事件是否被通知
+	mustGenerate(t, b)
+	if _, err := b.GenerateInBatches(10); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	if _, err := b.Generate(); err == nil {
+		t.Fatal("exhausted generator expects an error, but got nil")
+	}
+	if observer.generated != 11 || observer.overflows != 1 || observer.errors != 1 {
+		t.Errorf("Unexpected events: %+v, expected 11 generated, 1 overflow and 1 error", *observer)
+	}
+
+	// 测试移除观察者后是否不再通知
+	b.SetObserver(nil)
+	if _, err := b.Generate(); err == nil {
+		t.Fatal("exhausted generator expects an error, but got nil")
+	}
+	if observer.errors != 1 {
+		t.Errorf("Unexpected errors: %d, expected %d", observer.errors, 1)
+	}
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Option configures a generator constructed by New
+type Option func(*Butterfly) error
+
+// WithNodeID fixes the node ID of the generator, it must be unique among all generators
+func WithNodeID(nodeID int64) Option {
+	return func(b *Butterfly) error {
+		b.nodeID = nodeID
+		return nil
+	}
+}
+
+// WithStartTimestamp sets the timestamp the generator starts at instead of reading the clock, in the timestamp unit
+// of the layout: milliseconds by default, microseconds or seconds with the Resolution of WithConfig
+func WithStartTimestamp(timestamp int64) Option {
+	return func(b *Butterfly) error {
+		if timestamp < 0 {
+			return fmt.Errorf("the start timestamp %d must not be negative", timestamp)
+		}
+		b.timestamp = timestamp
+		return nil
+	}
+}
+
+// WithClockMode marks the generator as driven by GenerateWithClock, so its start timestamp
+// may not be later than the clock plus maxFutureSkew
+func WithClockMode() Option {
+	return func(b *Butterfly) error {
+		b.clockBased = true
+		return nil
+	}
+}
+
+// WithEpoch sets the time since the Unix epoch, in the timestamp unit, that is subtracted from the timestamp before packing
+func WithEpoch(epoch int64) Option {
+	return func(b *Butterfly) error {
+		b.epoch = epoch
+		return nil
+	}
+}
+
+// WithEpochTime is like WithEpoch but takes the epoch as a time, which New converts to the timestamp unit of the layout,
+// avoiding epochs passed in seconds or nanoseconds by mistake. The epoch must not be after the start timestamp.
+func WithEpochTime(t time.Time) Option {
+	return func(b *Butterfly) error {
+		if t.IsZero() {
+			return errors.New("the epoch time must not be zero")
+		}
+		b.epochTime = t
+		return nil
+	}
+}
+
+// WithClock sets the clock of the generator, which must return the Unix time in the timestamp unit, milliseconds by default
+func WithClock(clock func() int64) Option {
+	return func(b *Butterfly) error {
+		if clock == nil {
+			return errors.New("the clock must not be nil")
+		}
+		b.clock = clock
+		b.milliClock = false
+		return nil
+	}
+}
+
+// WithCachedClock makes GenerateWithClock read the clock only once the sequences of the current timestamp are used up,
+// saving a clock reading per id in bursts. The timestamp then lags the clock until MaxIDsPerMillisecond ids are generated,
+// so only use it for generators that are busy enough for the lag to stay small.
+func WithCachedClock() Option {
+	return func(b *Butterfly) error {
+		b.cachedClock = true
+		return nil
+	}
+}
+
+// WithConfig sets the bit layout of the generator
+func WithConfig(cfg Config) Option {
+	return func(b *Butterfly) error {
+		l, err := cfg.layout()
+		if err != nil {
+			return err
+		}
+		b.layout = l
+		b.config = cfg
+		return nil
+	}
+}
+
+// WithRandomSequenceStart starts the high and low sequences at random values, so that the first ids after a restart
+// are not simply timestamp, node ID and sequence 0. This is a modest obfuscation, not a cryptographic guarantee:
+// later ids still count up from the start. A clock-based generator randomizes the sequences again on its first
+// new millisecond, so that millisecond, whether it is the start or the first one the clock moves to, has fewer ids left.
+func WithRandomSequenceStart() Option {
+	return func(b *Butterfly) error {
+		b.randomStart = true
+		return nil
+	}
+}
+
+// randomizeSequences sets the sequences to random values within the layout
+func (b *Butterfly) randomizeSequences() error {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return fmt.Errorf("failed to read the random sequence start: %w", err)
+	}
+	b.highSequence = int64(binary.BigEndian.Uint64(buf[:8])) & b.layout.maxHighSequence
+	b.lowSequence = int64(binary.BigEndian.Uint64(buf[8:])) & b.layout.maxLowSequence
+	return nil
+}
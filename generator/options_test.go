@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	epoch := int64(1577836800000)
+	b, err := New(WithNodeID(7), WithStartTimestamp(epoch+1000), WithEpoch(epoch), WithClock(func() int64 { return 0 }))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试各选项是否生效
+	id := mustGenerate(t, b)
+	timestamp, _, _, _, err := b.ParseID(id)
+	if err != nil {
+		t.Fatalf("failed to parse id %d: %v", id, err)
+	}
+	if timestamp != epoch+1000 {
+		t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, epoch+1000)
+	}
+	if b.NodeID() != 7 {
+		t.Errorf("Unexpected node ID: %d, expected %d", b.NodeID(), 7)
+	}
+
+	// 测试未指定起始时间戳时是否读取时钟
+	b, err = New(WithClock(func() int64 { return 42 }))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.timestamp != 42 {
+		t.Errorf("Unexpected timestamp: %d, expected %d", b.timestamp, 42)
+	}
+
+	// 测试所有选项应用后是否统一校验
+	invalidOptions := [][]Option{
+		{WithNodeID(-1)},
+		{WithNodeID(maxNodeID + 1)},
+		{WithConfig(Config{TimestampBits: 41, HighSequenceBits: 8, NodeBits: 4, LowSequenceBits: 10}), WithNodeID(16)},
+		{WithStartTimestamp(-1)},
+		{WithStartTimestamp(epoch - 1), WithEpoch(epoch)},
+		{WithStartTimestamp(maxTimestamp + 1)},
+		{WithClock(nil)},
+	}
+	for i, opts := range invalidOptions {
+		if _, err := New(opts...); err == nil {
+			t.Errorf("options %d expect an error, but got nil", i)
+		}
+	}
+}
+
+func TestWithStartTimestamp(t *testing.T) {
+	now := time.Now().UnixMilli()
+
+	// 测试起始时间戳的边界值
+	cases := []struct {
+		timestamp int64
+		clockMode bool
+		valid     bool
+	}{
+		{timestamp: -1, valid: false},
+		{timestamp: 0, valid: true},
+		{timestamp: now, valid: true},
+		{timestamp: maxTimestamp, valid: true},
+		{timestamp: maxTimestamp + 1, valid: false},
+		{timestamp: now, clockMode: true, valid: true},
+		{timestamp: now + int64(time.Hour/time.Millisecond), clockMode: true, valid: false},
+		{timestamp: maxTimestamp, clockMode: true, valid: false},
+	}
+	for _, c := range cases {
+		opts := []Option{WithStartTimestamp(c.timestamp)}
+		if c.clockMode {
+			opts = append(opts, WithClockMode())
+		}
+		_, err := New(opts...)
+		if c.valid && err != nil {
+			t.Errorf("%+v expects no error, but got %v", c, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%+v expects an error, but got nil", c)
+		}
+	}
+}
+
+func TestWithRandomSequenceStart(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LowSequenceBits, cfg.NodeBits = 4, 10
+
+	// 测试起始序列号是否随机且在范围内，生成的ID仍然递增
+	starts := make(map[int64]bool)
+	for i := 0; i < 20; i++ {
+		b, err := New(WithStartTimestamp(1000), WithConfig(cfg), WithRandomSequenceStart())
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		if b.highSequence < 0 || b.highSequence > b.layout.maxHighSequence || b.lowSequence < 0 || b.lowSequence > b.layout.maxLowSequence {
+			t.Fatalf("Unexpected sequences: %d/%d", b.highSequence, b.lowSequence)
+		}
+		starts[b.compose()] = true
+
+		lastID := b.compose()
+		for j := 0; j < 100; j++ {
+			id := mustGenerate(t, b)
+			if id <= lastID {
+				t.Fatalf("ID not incrementing: %d, %d on %d times loop", id, lastID, j)
+			}
+			lastID = id
+		}
+	}
+	if len(starts) < 2 {
+		t.Errorf("Unexpected distinct starts: %d, expected random starts", len(starts))
+	}
+}
+
+func TestWithRandomSequenceStart_ClockMode(t *testing.T) {
+	// 测试时钟模式下第一个新毫秒的序列号是否随机，之后的毫秒从0开始
+	randomized := 0
+	for i := 0; i < 20; i++ {
+		var now int64 = 1000
+		b, err := New(WithClockMode(), WithNodeID(1), WithClock(func() int64 { return now }), WithRandomSequenceStart())
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		now++
+		id, err := b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if c := Decompose(id); c.Timestamp != now {
+			t.Errorf("Unexpected timestamp: %d, expected %d", c.Timestamp, now)
+		} else if c.HighSequence != 0 || c.LowSequence != 0 {
+			randomized++
+		}
+
+		now++
+		id, err = b.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if c := Decompose(id); c.HighSequence != 0 || c.LowSequence != 0 {
+			t.Errorf("Unexpected sequences of the second tick: %d/%d, expected 0/0", c.HighSequence, c.LowSequence)
+		}
+	}
+	if randomized == 0 {
+		t.Error("first ticks expect random sequences, but all of them started at 0")
+	}
+}
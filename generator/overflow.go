@@ -0,0 +1,26 @@
+package generator
+
+import "fmt"
+
+// OverflowPolicy decides what GenerateWithClock does when the sequences of the current millisecond are used up
+type OverflowPolicy int
+
+const (
+	// PolicyAdvanceLogical pushes the timestamp ahead of the clock, so bursts never block but ids may decode to the future
+	PolicyAdvanceLogical OverflowPolicy = iota
+	// PolicyError returns ErrExhausted until the clock advances, so the timestamps never run ahead of it
+	PolicyError
+	// PolicyWaitClock blocks until the clock advances, holding the generator locked meanwhile
+	PolicyWaitClock
+)
+
+// WithOverflowPolicy sets the overflow policy of a clock-based generator, PolicyAdvanceLogical is the default
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(b *Butterfly) error {
+		if policy < PolicyAdvanceLogical || policy > PolicyWaitClock {
+			return fmt.Errorf("invalid overflow policy %d", policy)
+		}
+		b.overflowPolicy = policy
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithOverflowPolicy(t *testing.T) {
+	var now int64 = 1000
+	clock := func() int64 { return now }
+
+	// 测试推进策略是否将时间戳推到时钟之前
+	b, err := New(WithClockMode(), WithClock(clock), WithOverflowPolicy(PolicyAdvanceLogical))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.lowSequence = maxLowSequence
+	if id, err := b.GenerateWithClock(); err != nil || Decompose(id).Timestamp != now+1 {
+		t.Errorf("Unexpected generation: %d, %v, expected timestamp %d", id, err, now+1)
+	}
+
+	// 测试报错策略是否在时钟推进前返回错误
+	b, err = New(WithClockMode(), WithClock(clock), WithOverflowPolicy(PolicyError))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.lowSequence = maxLowSequence
+	if _, err := b.GenerateWithClock(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrExhausted)
+	}
+	now++
+	if id, err := b.GenerateWithClock(); err != nil || Decompose(id).Timestamp != now {
+		t.Errorf("Unexpected generation: %d, %v, expected timestamp %d", id, err, now)
+	}
+
+	// 测试等待策略是否阻塞到时钟推进
+	reads := 0
+	b, err = New(WithClockMode(), WithOverflowPolicy(PolicyWaitClock), WithClock(func() int64 {
+		reads++
+		if reads > 3 {
+			return now + 1
+		}
+		return now
+	}))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	b.highSequence = maxHighSequence
+	b.lowSequence = maxLowSequence
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if c := Decompose(id); c.Timestamp != now+1 || c.HighSequence != 0 || c.LowSequence != 0 {
+		t.Errorf("Unexpected components: %+v, expected timestamp %d and sequences 0", c, now+1)
+	}
+
+	// 测试非法的策略与非时钟模式是否被拒绝
+	if _, err := New(WithOverflowPolicy(PolicyWaitClock)); err == nil {
+		t.Error("policy without the clock mode expects an error, but got nil")
+	}
+	if _, err := New(WithClockMode(), WithOverflowPolicy(OverflowPolicy(3))); err == nil {
+		t.Error("invalid policy expects an error, but got nil")
+	}
+}
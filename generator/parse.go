@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseID decomposes an id produced by Butterfly.Generate back into the fields it was packed from.
+// It is the exact inverse of the packing in Generate for the default layout and epoch.
+func ParseID(id int64) (timestamp, highSequence, nodeID, lowSequence int64, err error) {
+	return defaultLayout.parse(id, 0)
+}
+
+// ParseID is like the package level ParseID but honors the layout and epoch of the generator
+func (b *Butterfly) ParseID(id int64) (timestamp, highSequence, nodeID, lowSequence int64, err error) {
+	return b.layout.parse(id, b.epoch)
+}
+
+func (l layout) parse(id, epoch int64) (timestamp, highSequence, nodeID, lowSequence int64, err error) {
+	if id < 0 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid id %d: id must not be negative", id)
+	}
+	if l.marker != 0 {
+		if err := l.checkMarker(id); err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+
+	timestamp, highSequence, nodeID, lowSequence = l.fields(id, epoch)
+	return timestamp, highSequence, nodeID, lowSequence, nil
+}
+
+// Components are the fields packed into an id, named to avoid transposing them at the call site
+type Components struct {
+	Timestamp    int64     // 时间戳，已加上纪元
+	HighSequence int64     // 高序列号
+	NodeID       int64     // 节点ID
+	LowSequence  int64     // 低序列号
+	Time         time.Time // 时间戳对应的时间
+}
+
+// Decompose returns the components of an id produced with the default layout and epoch
+func Decompose(id int64) Components {
+	return defaultLayout.decompose(id, 0)
+}
+
+// Decompose is like the package level Decompose but honors the layout and epoch of the generator
+func (b *Butterfly) Decompose(id int64) Components {
+	return b.layout.decompose(id, b.epoch)
+}
+
+func (l layout) decompose(id, epoch int64) Components {
+	timestamp, highSequence, nodeID, lowSequence := l.fields(id, epoch)
+	return Components{
+		Timestamp:    timestamp,
+		HighSequence: highSequence,
+		NodeID:       nodeID,
+		LowSequence:  lowSequence,
+		Time:         l.toTime(timestamp),
+	}
+}
+
+// fields unpacks the fields of decompose without building its Time, which dominates the cost, for the hot paths
+func (l layout) fields(id, epoch int64) (timestamp, highSequence, nodeID, lowSequence int64) {
+	return (id>>l.timeShift)&l.maxTimestamp + epoch,
+		(id >> l.highSequenceShift) & l.maxHighSequence,
+		(id >> l.nodeIDShift) & l.maxNodeID,
+		(id >> l.lowSequenceShift) & l.maxLowSequence
+}
+
+// ExtractTime returns the creation time packed into an id produced with the default layout and epoch
+func ExtractTime(id int64) time.Time {
+	return defaultLayout.extractTime(id, 0)
+}
+
+// ExtractTime is like the package level ExtractTime but honors the layout and epoch of the generator
+func (b *Butterfly) ExtractTime(id int64) time.Time {
+	return b.layout.extractTime(id, b.epoch)
+}
+
+func (l layout) extractTime(id, epoch int64) time.Time {
+	return l.toTime((id>>l.timeShift)&l.maxTimestamp + epoch)
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseID(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+
+	// 测试解析后重新组合是否得到原ID
+	for i := 0; i < 1000; i++ {
+		id := mustGenerate(t, b)
+		timestamp, highSequence, nodeID, lowSequence, err := ParseID(id)
+		if err != nil {
+			t.Fatalf("failed to parse id %d: %v", id, err)
+		}
+
+		composed := (timestamp << timeShift) |
+			(highSequence << highSequenceShift) |
+			(nodeID << nodeIDShift) |
+			lowSequence
+		if composed != id {
+			t.Errorf("re-composed id %d differs from the original %d", composed, id)
+		}
+		if timestamp != b.timestamp {
+			t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, b.timestamp)
+		}
+	}
+
+	// 测试负数ID是否被拒绝
+	if _, _, _, _, err := ParseID(-1); err == nil {
+		t.Error("negative id expects an error, but got nil")
+	}
+}
+
+func TestExtractTime(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+
+	id := mustGenerate(t, b)
+	if created := ExtractTime(id); !created.Equal(time.UnixMilli(initTimestamp)) {
+		t.Errorf("Unexpected creation time: %v, expected %v", created, time.UnixMilli(initTimestamp))
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+	b.highSequence = 3
+	b.nodeID = 12
+
+	// 测试各字段是否与生成时一致
+	id := mustGenerate(t, b)
+	c := Decompose(id)
+	expected := Components{Timestamp: initTimestamp, HighSequence: 3, NodeID: 12, LowSequence: 1, Time: time.UnixMilli(initTimestamp)}
+	if c != expected {
+		t.Errorf("Unexpected components: %+v, expected %+v", c, expected)
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(int64(0), int64(0), int64(0), int64(0))
+	f.Add(int64(maxTimestamp), int64(maxHighSequence), int64(maxNodeID), int64(maxLowSequence))
+	f.Add(int64(maxTimestamp), int64(0), int64(0), int64(0))
+	f.Add(int64(0), int64(maxHighSequence), int64(0), int64(0))
+	f.Add(int64(0), int64(0), int64(maxNodeID), int64(0))
+	f.Add(int64(0), int64(0), int64(0), int64(maxLowSequence))
+
+	// 测试任意合法字段打包后解析是否得到原字段
+	f.Fuzz(func(t *testing.T, timestamp, highSequence, nodeID, lowSequence int64) {
+		b := NewButterfly(timestamp & maxTimestamp)
+		b.highSequence = highSequence & maxHighSequence
+		b.nodeID = nodeID & maxNodeID
+		b.lowSequence = lowSequence & maxLowSequence
+
+		id := b.compose()
+		ts, hs, n, ls, err := ParseID(id)
+		if err != nil {
+			t.Fatalf("failed to parse id %d: %v", id, err)
+		}
+		if ts != b.timestamp || hs != b.highSequence || n != b.nodeID || ls != b.lowSequence {
+			t.Errorf("Unexpected fields of id %d: %d/%d/%d/%d, expected %d/%d/%d/%d", id, ts, hs, n, ls, b.timestamp, b.highSequence, b.nodeID, b.lowSequence)
+		}
+	})
+}
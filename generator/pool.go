@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+// Pool round-robins across clock-based generators with distinct node IDs,
+// multiplying the ids available per millisecond while staying collision-free
+type Pool struct {
+	noCopy  noCopy       // 禁止复制
+	members []*Butterfly // 成员生成器
+	next    uint64       // 下一个使用的成员下标
+}
+
+func NewPool(nodeIDs []int64) (*Pool, error) {
+	if len(nodeIDs) == 0 {
+		return nil, errors.New("failed to construct the pool: no node ID is given")
+	}
+
+	pool := &Pool{}
+	seen := make(map[int64]bool, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if seen[nodeID] {
+			return nil, fmt.Errorf("failed to construct the pool: the node ID %d is duplicated", nodeID)
+		}
+		seen[nodeID] = true
+
+		member, err := NewClockBased(nodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct the pool: %w", err)
+		}
+		pool.members = append(pool.members, member)
+	}
+	return pool, nil
+}
+
+// NewShardedPool constructs a pool of shards members with the consecutive node IDs from firstNodeID on.
+// Spreading callers over several mutexes cuts contention without the complexity of AtomicButterfly,
+// GOMAXPROCS shards is a good start.
+func NewShardedPool(shards int, firstNodeID int64) (*Pool, error) {
+	if shards < 1 {
+		return nil, fmt.Errorf("failed to construct the pool: invalid shards %d, at least 1 is required", shards)
+	}
+
+	nodeIDs := make([]int64, shards)
+	for i := range nodeIDs {
+		nodeIDs[i] = firstNodeID + int64(i)
+	}
+	return NewPool(nodeIDs)
+}
+
+// Generate takes an id from the next member, falling through to the others if that member fails
+func (p *Pool) Generate() (int64, error) {
+	start := atomic.AddUint64(&p.next, 1)
+	var err error
+	for i := 0; i < len(p.members); i++ {
+		member := p.members[(start+uint64(i))%uint64(len(p.members))]
+		var id int64
+		if id, err = member.GenerateWithClock(); err == nil {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("all members of the pool failed: %w", err)
+}
+
+// GenerateInBatches generates count ids, on error it returns the ids generated so far along with the error
+func (p *Pool) GenerateInBatches(count int) ([]int64, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("invalid count %d: count must not be negative", count)
+	}
+
+	idList := make([]int64, 0, count)
+	for i := 0; i < count; i++ {
+		id, err := p.Generate()
+		if err != nil {
+			return idList, err
+		}
+		idList = append(idList, id)
+	}
+	return idList, nil
+}
+
+// NodeIDs returns the sorted node IDs held by the members of the pool
+func (p *Pool) NodeIDs() []int64 {
+	nodeIDs := make([]int64, 0, len(p.members))
+	for _, member := range p.members {
+		nodeIDs = append(nodeIDs, member.NodeID())
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	return nodeIDs
+}
+
+// IsSaturated reports whether every member has used up the sequences of the current millisecond,
+// so that the next id pushes a timestamp ahead of the clock. A pool that is often saturated needs more members.
+func (p *Pool) IsSaturated() bool {
+	for _, member := range p.members {
+		if !member.saturated() {
+			return false
+		}
+	}
+	return true
+}
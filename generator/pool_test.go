@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPool_Generate(t *testing.T) {
+	pool, err := NewPool([]int64{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("failed to construct the pool: %v", err)
+	}
+
+	// 测试并发生成的ID是否无重复
+	const goroutines = 8
+	const perGoroutine = 10000
+	var mutex sync.Mutex
+	seen := make(map[int64]bool, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				id, err := pool.Generate()
+				if err != nil {
+					t.Errorf("failed to generate id: %v", err)
+					return
+				}
+				mutex.Lock()
+				if seen[id] {
+					t.Errorf("duplicate id: %d", id)
+				}
+				seen[id] = true
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 测试某个成员耗尽后是否转而使用其他成员
+	pool.members[0].timestamp = maxTimestamp
+	pool.members[0].highSequence = maxHighSequence
+	pool.members[0].lowSequence = maxLowSequence
+	for i := 0; i < len(pool.members)*2; i++ {
+		if _, err := pool.Generate(); err != nil {
+			t.Fatalf("failed to generate id with an exhausted member: %v", err)
+		}
+	}
+
+	// 测试非法的节点ID是否被拒绝
+	for _, nodeIDs := range [][]int64{nil, {1, 1}, {-1}, {maxNodeID + 1}} {
+		if _, err := NewPool(nodeIDs); err == nil {
+			t.Errorf("node IDs %v expect an error, but got nil", nodeIDs)
+		}
+	}
+}
+
+func TestPool_NodeIDs(t *testing.T) {
+	pool, err := NewPool([]int64{7, 3, 5})
+	if err != nil {
+		t.Fatalf("failed to construct the pool: %v", err)
+	}
+
+	// 测试节点ID是否排序返回
+	nodeIDs := pool.NodeIDs()
+	if len(nodeIDs) != 3 || nodeIDs[0] != 3 || nodeIDs[1] != 5 || nodeIDs[2] != 7 {
+		t.Errorf("Unexpected node IDs: %v, expected %v", nodeIDs, []int64{3, 5, 7})
+	}
+}
+
+func TestPool_IsSaturated(t *testing.T) {
+	var now int64 = 1000
+	pool := &Pool{}
+	for nodeID := int64(0); nodeID < 2; nodeID++ {
+		member, err := NewClockBasedWithClock(nodeID, func() int64 { return now })
+		if err != nil {
+			t.Fatalf("failed to construct the generator: %v", err)
+		}
+		pool.members = append(pool.members, member)
+	}
+	if pool.IsSaturated() {
+		t.Error("fresh pool expects not to be saturated")
+	}
+
+	// 测试所有成员用尽当前毫秒的序列号后是否饱和，成员构造时已占用序列号0
+	for i := int64(0); i < 2*(MaxIDsPerMillisecond()-1); i++ {
+		if pool.IsSaturated() {
+			t.Fatalf("pool expects not to be saturated on %d times loop", i)
+		}
+		if _, err := pool.Generate(); err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+	}
+	if !pool.IsSaturated() {
+		t.Error("pool expects to be saturated, but is not")
+	}
+
+	// 测试时钟推进后是否不再饱和
+	now++
+	if pool.IsSaturated() {
+		t.Error("pool expects not to be saturated after the clock advances")
+	}
+}
+
+func TestNewShardedPool(t *testing.T) {
+	// 测试分片是否使用连续的节点ID
+	pool, err := NewShardedPool(4, 10)
+	if err != nil {
+		t.Fatalf("failed to construct the pool: %v", err)
+	}
+	nodeIDs := pool.NodeIDs()
+	for i, nodeID := range nodeIDs {
+		if nodeID != 10+int64(i) {
+			t.Errorf("Unexpected node ID: %d, expected %d", nodeID, 10+int64(i))
+		}
+	}
+	if len(nodeIDs) != 4 {
+		t.Errorf("Unexpected length of node IDs: %d, expected %d", len(nodeIDs), 4)
+	}
+
+	for _, shards := range []int{0, maxNodeID + 2} {
+		if _, err := NewShardedPool(shards, 0); err == nil {
+			t.Errorf("shards %d expect an error, but got nil", shards)
+		}
+	}
+}
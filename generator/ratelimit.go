@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitMode decides what a rate-limited generator does when the rate is exceeded
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks until the bucket holds enough tokens, without holding the generator locked,
+	// or until the context of GenerateContext or Stream is done
+	RateLimitWait RateLimitMode = iota
+	// RateLimitError returns ErrRateLimited at once
+	RateLimitError
+)
+
+// WithRateLimit caps every generating method at perSecond ids per second with a token bucket holding one second of tokens,
+// GenerateInBatches and Fill take as many tokens as ids they generate, and only requests passing their pre-checks take any
+func WithRateLimit(perSecond int, mode RateLimitMode) Option {
+	return func(b *Butterfly) error {
+		if perSecond <= 0 {
+			return fmt.Errorf("invalid rate %d: the rate must be positive", perSecond)
+		}
+		if mode < RateLimitWait || mode > RateLimitError {
+			return fmt.Errorf("invalid rate limit mode %d", mode)
+		}
+		b.limiter = &rateLimiter{
+			perSecond: float64(perSecond),
+			tokens:    float64(perSecond),
+			last:      time.Now(),
+			wait:      mode == RateLimitWait,
+		}
+		return nil
+	}
+}
+
+// rateLimiter is a token bucket refilled at perSecond tokens per second up to perSecond tokens
+type rateLimiter struct {
+	mutex     sync.Mutex
+	perSecond float64   // 每秒补充的令牌数，也是桶的容量
+	tokens    float64   // 桶内的令牌数，等待中的调用会使其为负
+	last      time.Time // 上次补充令牌的时间
+	wait      bool      // 超出速率时是否等待
+}
+
+// reserve takes n tokens and returns how long to wait until the bucket has refilled them,
+// or takes nothing and returns ErrRateLimited if the bucket holds fewer and waiting is not allowed
+func (l *rateLimiter) reserve(n int) (time.Duration, error) {
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > l.perSecond {
+		l.tokens = l.perSecond
+	}
+	l.last = now
+
+	lack := float64(n) - l.tokens
+	if lack > 0 && !l.wait {
+		l.mutex.Unlock()
+		return 0, fmt.Errorf("%w: %d ids are requested but only %.0f tokens remain", ErrRateLimited, n, l.tokens)
+	}
+	l.tokens -= float64(n)
+	l.mutex.Unlock()
+
+	if lack <= 0 {
+		return 0, nil
+	}
+	return time.Duration(lack / l.perSecond * float64(time.Second)), nil
+}
+
+// fresh returns a full bucket of the same rate and mode, or nil for nil
+func (l *rateLimiter) fresh() *rateLimiter {
+	if l == nil {
+		return nil
+	}
+	return &rateLimiter{perSecond: l.perSecond, tokens: l.perSecond, last: time.Now(), wait: l.wait}
+}
+
+// refund gives back n tokens taken by a request that failed after all
+func (l *rateLimiter) refund(n int) {
+	l.mutex.Lock()
+	l.tokens += float64(n)
+	l.mutex.Unlock()
+}
+
+// throttle takes n tokens from the limiter, if any, once check passes, the caller must hold the mutex.
+// The mutex is released while waiting for the tokens, so check runs again afterwards on the state it may have changed.
+// If ctx is done during the wait or check fails afterwards, the tokens are given back.
+// As every generating method calls it before moving, it also refuses a closed generator, before and after the wait.
+func (b *Butterfly) throttle(ctx context.Context, n int, check func() error) error {
+	if b.closed {
+		return ErrClosed
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	if b.limiter == nil {
+		return nil
+	}
+	wait, err := b.limiter.reserve(n)
+	if err != nil || wait == 0 {
+		return err
+	}
+
+	b.mutex.Unlock()
+	timer := time.NewTimer(wait)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		err = ctx.Err()
+	case <-timer.C:
+	}
+	b.mutex.Lock()
+	if err == nil && b.closed {
+		err = ErrClosed
+	}
+	if err == nil && check != nil {
+		err = check()
+	}
+	if err != nil {
+		b.limiter.refund(n)
+	}
+	return err
+}
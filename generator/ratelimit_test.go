@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	b, err := New(WithRateLimit(200, RateLimitWait))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试等待模式下实际速率是否接近配置的速率，首秒的令牌桶是满的
+	start := time.Now()
+	for i := 0; i < 300; i++ {
+		mustGenerate(t, b)
+	}
+	if _, err := b.GenerateInBatches(100); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond || elapsed > 1500*time.Millisecond {
+		t.Errorf("Unexpected elapsed time: %v, expected about %v", elapsed, time.Second)
+	}
+
+	// 测试报错模式下超出速率是否返回ErrRateLimited
+	c, err := New(WithRateLimit(10, RateLimitError))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if _, err := c.GenerateInBatches(8); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if _, err := c.GenerateInBatches(3); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+	mustGenerate(t, c)
+	mustGenerate(t, c)
+	if _, err := c.Generate(); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+	if stats := c.Stats(); stats.Errors != 2 {
+		t.Errorf("Unexpected errors: %d, expected %d", stats.Errors, 2)
+	}
+
+	// 测试所有生成入口是否都受速率限制
+	d, err := New(WithRateLimit(5, RateLimitError))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if _, err := d.GenerateUnique(func(int64) bool { return false }); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if _, _, err := d.GenerateTimestamped(); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	if n, err := d.Fill(make([]int64, 3)); err != nil || n != 3 {
+		t.Fatalf("failed to fill ids: %d, %v", n, err)
+	}
+	if _, err := d.Fill(make([]int64, 1)); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+	if _, err := d.GenerateUnique(func(int64) bool { return false }); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+	if _, _, err := d.GenerateTimestamped(); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+	if _, err := d.GenerateContext(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+	if _, err := d.GenerateAt(d.epoch); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrRateLimited)
+	}
+
+	// 测试被拒绝的批量生成是否不消耗令牌
+	e, err := New(WithRateLimit(5, RateLimitError))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	e.timestamp, e.highSequence, e.nodeID, e.lowSequence = maxTimestamp, maxHighSequence, maxNodeID, maxLowSequence
+	if _, err := e.GenerateInBatches(5); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrTimestampOverflow)
+	}
+	e.timestamp, e.highSequence, e.nodeID, e.lowSequence = 0, 0, 0, 0
+	if _, err := e.GenerateInBatches(5); err != nil {
+		t.Errorf("failed to generate ids: %v", err)
+	}
+
+	// 测试等待令牌时上下文结束是否立即返回并归还令牌
+	f, err := New(WithRateLimit(10, RateLimitWait))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if _, err := f.GenerateInBatches(10); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := f.GenerateContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error: %v, expected %v", err, context.DeadlineExceeded)
+	}
+	ids, errs := f.Stream(ctx, 0)
+	for range ids {
+		t.Error("cancelled stream expects no id")
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("cancelled stream expects no error, but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Unexpected elapsed time: %v, expected the wait to stop with the context", elapsed)
+	}
+	if f.limiter.tokens < -0.5 {
+		t.Errorf("Unexpected tokens: %.2f, expected the cancelled requests to give theirs back", f.limiter.tokens)
+	}
+
+	for _, opt := range []Option{WithRateLimit(0, RateLimitWait), WithRateLimit(1, RateLimitMode(2))} {
+		if _, err := New(opt); err == nil {
+			t.Error("invalid rate limit expects an error, but got nil")
+		}
+	}
+}
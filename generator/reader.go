@@ -0,0 +1,32 @@
+package generator
+
+import "encoding/binary"
+
+// Reader is an io.Reader emitting successive ids of a generator as 8 byte big-endian values
+type Reader struct {
+	generator Generator
+	buf       [8]byte // 当前ID的编码
+	pending   []byte  // 当前ID尚未读取的字节
+}
+
+func NewReader(generator Generator) *Reader {
+	return &Reader{generator: generator}
+}
+
+// Read fills p with encoded ids, keeping the bytes of an id that do not fit for the next call.
+// A generation error is returned along with the bytes read before it.
+func (r *Reader) Read(p []byte) (int, error) {
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	for n < len(p) {
+		id, err := r.generator.Generate()
+		if err != nil {
+			return n, err
+		}
+		binary.BigEndian.PutUint64(r.buf[:], uint64(id))
+		copied := copy(p[n:], r.buf[:])
+		r.pending = r.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
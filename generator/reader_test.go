@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	r := NewReader(NewButterfly(initTimestamp))
+	expected := NewButterfly(initTimestamp)
+
+	// 测试跨越ID边界的分段读取是否得到连续的ID
+	var data []byte
+	for _, size := range []int{3, 8, 13, 1, 7, 16, 0, 24} {
+		p := make([]byte, size)
+		n, err := r.Read(p)
+		if err != nil || n != size {
+			t.Fatalf("Unexpected read: %d, %v, expected %d bytes", n, err, size)
+		}
+		data = append(data, p...)
+	}
+	for i := 0; i+8 <= len(data); i += 8 {
+		id := int64(binary.BigEndian.Uint64(data[i : i+8]))
+		if e := mustGenerate(t, expected); id != e {
+			t.Errorf("Unexpected id: %d, expected %d at %d offset", id, e, i)
+		}
+	}
+
+	// 测试生成失败是否作为读取错误返回
+	b := NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	n, err := io.ReadFull(NewReader(b), make([]byte, 16))
+	if !errors.Is(err, ErrTimestampOverflow) || n != 8 {
+		t.Errorf("Unexpected read: %d, %v, expected 8 bytes and %v", n, err, ErrTimestampOverflow)
+	}
+}
@@ -0,0 +1,135 @@
+//go:build redis
+
+package generator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisLeaseTTL is how long a claimed node ID survives without being renewed, a variable so tests can shorten it
+var redisLeaseTTL = 30 * time.Second
+
+// claimScript sets the first free key among KEYS[1]:0 .. KEYS[1]:ARGV[3] and returns its node ID, or -1 if all are taken.
+// KEYS[1] is a hash tag such as {key}, so that on a cluster all the keys it builds live in the slot of KEYS[1].
+var claimScript = redis.NewScript(`
+for i = 0, tonumber(ARGV[3]) do
+	if redis.call('SET', KEYS[1] .. ':' .. i, ARGV[1], 'NX', 'PX', ARGV[2]) then
+		return i
+	end
+end
+return -1
+`)
+
+// renewScript extends the lease only if it is still owned by the token
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lease only if it is still owned by the token
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// NewWithRedisMachine constructs a clock-based generator with the lowest node ID not leased by another
+// generator sharing key. The lease is renewed in the background and returned on Close. If a renewal finds the lease
+// expired, or renewals keep failing until the last successful one is two thirds of redisLeaseTTL old, another
+// generator may own the node ID soon or already, so the generator is closed and returns ErrClosed.
+func NewWithRedisMachine(ctx context.Context, client redis.UniversalClient, key string) (*Butterfly, error) {
+	token, err := leaseToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the lease token: %w", err)
+	}
+
+	slotKey := "{" + key + "}"
+	claimedAt := time.Now()
+	nodeID, err := claimScript.Run(ctx, client, []string{slotKey}, token, redisLeaseTTL.Milliseconds(), maxNodeID).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim a node ID from redis: %w", err)
+	}
+	if nodeID < 0 {
+		return nil, fmt.Errorf("failed to claim a node ID from redis: all %d node IDs of %q are leased", maxNodeID+1, key)
+	}
+
+	b, err := NewClockBased(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaseKey := fmt.Sprintf("%s:%d", slotKey, nodeID)
+	heartbeatCtx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	ttl := redisLeaseTTL
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		// renewedAt is taken before the claim or renewal is sent, so the lease lives at least ttl after it
+		renewedAt := claimedAt
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				// the timeout keeps a hanging renewal from outliving the safety margin of ttl/3
+				sentAt := time.Now()
+				renewCtx, cancelRenew := context.WithTimeout(heartbeatCtx, ttl/6)
+				renewed, err := renewScript.Run(renewCtx, client, []string{leaseKey}, token, ttl.Milliseconds()).Int64()
+				cancelRenew()
+				if heartbeatCtx.Err() != nil {
+					return
+				}
+				if err == nil && renewed != 0 {
+					renewedAt = sentAt
+					continue
+				}
+
+				b.mutex.Lock()
+				switch {
+				case err == nil:
+					b.logf("lost the lease of the node ID %d, closing the generator", nodeID)
+					b.closed = true
+				case time.Since(renewedAt) >= ttl-ttl/3:
+					b.logf("failed to renew the lease of the node ID %d since %v, closing the generator: %v", nodeID, renewedAt, err)
+					b.closed = true
+				default:
+					b.logf("failed to renew the lease of the node ID %d: %v", nodeID, err)
+				}
+				closed := b.closed
+				b.mutex.Unlock()
+				if closed {
+					return
+				}
+			}
+		}
+	}()
+
+	b.release = func() error {
+		cancel()
+		<-stopped
+		if err := releaseScript.Run(context.Background(), client, []string{leaseKey}, token).Err(); err != nil {
+			return fmt.Errorf("failed to release the node ID %d to redis: %w", nodeID, err)
+		}
+		return nil
+	}
+	return b, nil
+}
+
+func leaseToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,121 @@
+//go:build redis
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisClient connects to the server in REDIS_ADDR, skipping the test if there is none
+func newRedisClient(t *testing.T) *redis.Client {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR is not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("failed to connect to redis at %s: %v", addr, err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// shortenRedisLeaseTTL sets redisLeaseTTL to ttl for the duration of the test
+func shortenRedisLeaseTTL(t *testing.T, ttl time.Duration) {
+	old := redisLeaseTTL
+	redisLeaseTTL = ttl
+	t.Cleanup(func() { redisLeaseTTL = old })
+}
+
+func TestNewWithRedisMachine(t *testing.T) {
+	client := newRedisClient(t)
+	shortenRedisLeaseTTL(t, 600*time.Millisecond)
+	ctx := context.Background()
+	key := "butterfly-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	slot := func(nodeID int64) string { return "{" + key + "}:" + strconv.FormatInt(nodeID, 10) }
+	t.Cleanup(func() { client.Del(ctx, slot(0), slot(1), slot(2)) })
+
+	// 测试是否认领最小的空闲节点ID
+	if err := client.Set(ctx, slot(0), "other", time.Minute).Err(); err != nil {
+		t.Fatalf("failed to occupy the slot: %v", err)
+	}
+	b, err := NewWithRedisMachine(ctx, client, key)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	c, err := NewWithRedisMachine(ctx, client, key)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if b.NodeID() != 1 || c.NodeID() != 2 {
+		t.Errorf("Unexpected node IDs: %d and %d, expected 1 and 2", b.NodeID(), c.NodeID())
+	}
+
+	// 测试租约是否在超过TTL后仍被续期
+	time.Sleep(2 * redisLeaseTTL)
+	if n, err := client.Exists(ctx, slot(1)).Result(); err != nil || n != 1 {
+		t.Errorf("Unexpected lease: %d, %v, expected it renewed", n, err)
+	}
+	if _, err := b.GenerateWithClock(); err != nil {
+		t.Errorf("failed to generate id: %v", err)
+	}
+
+	// 测试丢失租约时是否关闭生成器
+	if err := client.Del(ctx, slot(2)).Err(); err != nil {
+		t.Fatalf("failed to delete the lease: %v", err)
+	}
+	time.Sleep(redisLeaseTTL)
+	if _, err := c.GenerateWithClock(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("failed to close the generator: %v", err)
+	}
+
+	// 测试关闭时是否归还节点ID
+	if err := b.Close(); err != nil {
+		t.Fatalf("failed to close the generator: %v", err)
+	}
+	if n, err := client.Exists(ctx, slot(1)).Result(); err != nil || n != 0 {
+		t.Errorf("Unexpected lease: %d, %v, expected it released", n, err)
+	}
+	d, err := NewWithRedisMachine(ctx, client, key)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	defer d.Close()
+	if d.NodeID() != 1 {
+		t.Errorf("Unexpected node ID: %d, expected %d", d.NodeID(), 1)
+	}
+}
+
+func TestNewWithRedisMachine_RenewalFailure(t *testing.T) {
+	client := newRedisClient(t)
+	shortenRedisLeaseTTL(t, 600*time.Millisecond)
+	ctx := context.Background()
+	key := "butterfly-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	t.Cleanup(func() { client.Del(ctx, "{"+key+"}:0") })
+
+	// 测试续期持续失败直到租约将要过期时是否关闭生成器
+	failing := redis.NewClient(&redis.Options{Addr: os.Getenv("REDIS_ADDR")})
+	b, err := NewWithRedisMachine(ctx, failing, key)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	defer b.Close()
+	failing.Close()
+	if _, err := b.GenerateWithClock(); err != nil {
+		t.Errorf("failed to generate id: %v", err)
+	}
+	time.Sleep(redisLeaseTTL)
+	if _, err := b.GenerateWithClock(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Unexpected error: %v, expected %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+)
+
+// registry holds the node IDs claimed by generators constructed with WithExclusiveNodeID
+var registry = struct {
+	mutex   sync.Mutex
+	nodeIDs map[int64]bool
+}{nodeIDs: map[int64]bool{}}
+
+// WithExclusiveNodeID claims the node ID in a process-wide registry, so that constructing a second
+// generator with the same node ID in this process fails. Close releases the node ID again.
+// It requires WithClockMode: a logical generator carries into the node ID, so claiming it would protect nothing.
+func WithExclusiveNodeID() Option {
+	return func(b *Butterfly) error {
+		b.exclusive = true
+		return nil
+	}
+}
+
+func claimNodeID(nodeID int64) error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if registry.nodeIDs[nodeID] {
+		return fmt.Errorf("the node ID %d is already claimed by another generator in this process", nodeID)
+	}
+	registry.nodeIDs[nodeID] = true
+	return nil
+}
+
+func releaseNodeID(nodeID int64) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	delete(registry.nodeIDs, nodeID)
+}
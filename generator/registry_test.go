@@ -0,0 +1,33 @@
+package generator
+
+import "testing"
+
+func TestWithExclusiveNodeID(t *testing.T) {
+	b, err := New(WithClockMode(), WithNodeID(100), WithExclusiveNodeID())
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试重复的节点ID是否被拒绝
+	if _, err := New(WithClockMode(), WithNodeID(100), WithExclusiveNodeID()); err == nil {
+		t.Error("duplicate node ID expects an error, but got nil")
+	}
+	if _, err := New(WithClockMode(), WithNodeID(100)); err != nil {
+		t.Errorf("non-exclusive generator expects no error, but got %v", err)
+	}
+
+	// 测试关闭后节点ID是否被释放
+	if err := b.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	b, err = New(WithClockMode(), WithNodeID(100), WithExclusiveNodeID())
+	if err != nil {
+		t.Fatalf("released node ID expects no error, but got %v", err)
+	}
+	b.Close()
+
+	// 测试逻辑模式的生成器是否拒绝独占节点ID
+	if _, err := New(WithNodeID(101), WithExclusiveNodeID()); err == nil {
+		t.Error("logical generator expects an error, but got nil")
+	}
+}
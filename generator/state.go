@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// savedState is the JSON form in which Save persists a generator
+type savedState struct {
+	Timestamp    int64 `json:"timestamp"`
+	HighSequence int64 `json:"highSequence"`
+	NodeID       int64 `json:"nodeID"`
+	LowSequence  int64 `json:"lowSequence"`
+	Epoch        int64 `json:"epoch"`
+}
+
+// State is the position of the last generated id, taken by State and applied by RestoreState
+type State struct {
+	Timestamp    int64 // 时间戳
+	HighSequence int64 // 高位序列号
+	NodeID       int64 // 节点ID
+	LowSequence  int64 // 低位序列号
+}
+
+// State returns the position of the last generated id in one locked read, for persisting it to a custom storage
+func (b *Butterfly) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return State{
+		Timestamp:    b.timestamp,
+		HighSequence: b.highSequence,
+		NodeID:       b.nodeID,
+		LowSequence:  b.lowSequence,
+	}
+}
+
+// RestoreState moves the generator to state, so that it continues right after the id state describes.
+// It rejects fields out of the layout of the generator and a change of the exclusive node ID, leaving the generator unchanged.
+func (b *Butterfly) RestoreState(state State) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := b.layout.validateNodeID(state.NodeID); err != nil {
+		return err
+	}
+	if b.exclusive && state.NodeID != b.nodeID {
+		return fmt.Errorf("failed to restore the generator state: the exclusive node ID %d cannot be changed", b.nodeID)
+	}
+	if state.Timestamp < b.epoch {
+		return fmt.Errorf("the timestamp %d is earlier than the epoch %d", state.Timestamp, b.epoch)
+	}
+	if state.Timestamp-b.epoch > b.layout.maxTimestamp {
+		return fmt.Errorf("%w: the timestamp %d exceeds the max timestamp %d since the epoch %d", ErrTimestampOverflow, state.Timestamp, b.layout.maxTimestamp, b.epoch)
+	}
+	if state.HighSequence < 0 || state.HighSequence > b.layout.maxHighSequence {
+		return fmt.Errorf("failed to restore the generator state: the high sequence %d is out of range [0, %d]", state.HighSequence, b.layout.maxHighSequence)
+	}
+	if state.LowSequence < 0 || state.LowSequence > b.layout.maxLowSequence {
+		return fmt.Errorf("failed to restore the generator state: the low sequence %d is out of range [0, %d]", state.LowSequence, b.layout.maxLowSequence)
+	}
+
+	b.timestamp = state.Timestamp
+	b.highSequence = state.HighSequence
+	b.nodeID = state.NodeID
+	b.lowSequence = state.LowSequence
+	return nil
+}
+
+// Save writes the position of the last generated id, so that a restarted process can resume past it with LoadState
+func (b *Butterfly) Save(w io.Writer) error {
+	current := b.State()
+	state := savedState{
+		Timestamp:    current.Timestamp,
+		HighSequence: current.HighSequence,
+		NodeID:       current.NodeID,
+		LowSequence:  current.LowSequence,
+		Epoch:        b.epoch,
+	}
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		return fmt.Errorf("failed to save the generator state: %w", err)
+	}
+	return nil
+}
+
+// LoadState constructs a generator with the default layout that continues right after the state written by Save
+func LoadState(r io.Reader) (*Butterfly, error) {
+	var state savedState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to load the generator state: %w", err)
+	}
+
+	b, err := New(WithStartTimestamp(state.Timestamp), WithNodeID(state.NodeID), WithEpoch(state.Epoch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the generator state: %w", err)
+	}
+	if state.HighSequence < 0 || state.HighSequence > b.layout.maxHighSequence {
+		return nil, fmt.Errorf("failed to load the generator state: the high sequence %d is out of range [0, %d]", state.HighSequence, b.layout.maxHighSequence)
+	}
+	if state.LowSequence < 0 || state.LowSequence > b.layout.maxLowSequence {
+		return nil, fmt.Errorf("failed to load the generator state: the low sequence %d is out of range [0, %d]", state.LowSequence, b.layout.maxLowSequence)
+	}
+	b.highSequence = state.HighSequence
+	b.lowSequence = state.LowSequence
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to load the generator state: %w", err)
+	}
+	return b, nil
+}
+
+// NewFromLastID constructs a clock-based generator with the default layout that continues right after id,
+// e.g. the max id of a table written by a generator of nodeID, so that the table itself is the state.
+// The node ID packed into id must be nodeID, and its timestamp may run at most maxFutureSkew ahead of the clock.
+func NewFromLastID(id, nodeID int64) (*Butterfly, error) {
+	timestamp, highSequence, packedNodeID, lowSequence, err := ParseID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to continue after the id %d: %w", id, err)
+	}
+	if packedNodeID != nodeID {
+		return nil, fmt.Errorf("failed to continue after the id %d: it was generated by the node ID %d instead of %d", id, packedNodeID, nodeID)
+	}
+
+	b, err := New(WithClockMode(), WithStartTimestamp(timestamp), WithNodeID(nodeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to continue after the id %d: %w", id, err)
+	}
+	b.highSequence = highSequence
+	b.lowSequence = lowSequence
+	return b, nil
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestButterfly_SaveAndLoadState(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	for i := 0; i < 12345; i++ {
+		mustGenerate(t, b)
+	}
+
+	// 测试恢复后的生成器是否从上次的位置继续
+	var buf bytes.Buffer
+	if err := b.Save(&buf); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	restored, err := LoadState(&buf)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if id, expected := mustGenerate(t, restored), mustGenerate(t, b); id != expected {
+			t.Fatalf("Unexpected id: %d, expected %d on %d times loop", id, expected, i)
+		}
+	}
+
+	// 测试非法状态是否被拒绝
+	invalidStates := []string{
+		`{"timestamp":1,"nodeID":8192}`,
+		`{"timestamp":1,"nodeID":-1}`,
+		`{"timestamp":1,"highSequence":256}`,
+		`{"timestamp":1,"lowSequence":2}`,
+		`{"timestamp":-1}`,
+		`not json`,
+	}
+	for _, state := range invalidStates {
+		if _, err := LoadState(strings.NewReader(state)); err == nil {
+			t.Errorf("state %s expects an error, but got nil", state)
+		}
+	}
+}
+
+func TestButterfly_RestoreState(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	for i := 0; i < 12345; i++ {
+		mustGenerate(t, b)
+	}
+
+	// 测试恢复到快照的生成器是否从快照的位置继续
+	state := b.State()
+	restored := NewButterfly(0)
+	if err := restored.RestoreState(state); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if restored.State() != state {
+		t.Errorf("Unexpected state: %+v, expected %+v", restored.State(), state)
+	}
+	for i := 0; i < 1000; i++ {
+		if id, expected := mustGenerate(t, restored), mustGenerate(t, b); id != expected {
+			t.Fatalf("Unexpected id: %d, expected %d on %d times loop", id, expected, i)
+		}
+	}
+
+	// 测试超出布局的状态是否被拒绝且不改变生成器
+	invalidStates := []State{
+		{Timestamp: 1, NodeID: maxNodeID + 1},
+		{Timestamp: 1, NodeID: -1},
+		{Timestamp: 1, HighSequence: maxHighSequence + 1},
+		{Timestamp: 1, LowSequence: maxLowSequence + 1},
+		{Timestamp: -1},
+		{Timestamp: maxTimestamp + 1},
+	}
+	state = restored.State()
+	for _, invalid := range invalidStates {
+		if err := restored.RestoreState(invalid); err == nil {
+			t.Errorf("state %+v expects an error, but got nil", invalid)
+		}
+	}
+	if restored.State() != state {
+		t.Errorf("Unexpected state: %+v, expected %+v", restored.State(), state)
+	}
+}
+
+func TestNewFromLastID(t *testing.T) {
+	b, err := NewClockBased(9)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	var last int64
+	for i := 0; i < 1000; i++ {
+		if last, err = b.GenerateWithClock(); err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+	}
+
+	// 测试从最后一个ID恢复的生成器是否生成更大的ID
+	restored, err := NewFromLastID(last, 9)
+	if err != nil {
+		t.Fatalf("failed to continue after %d: %v", last, err)
+	}
+	for i := 0; i < 1000; i++ {
+		id, err := restored.GenerateWithClock()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if id <= last {
+			t.Fatalf("ID not incrementing: %d, %d on %d times loop", id, last, i)
+		}
+		last = id
+	}
+
+	// 测试节点ID不符或ID非法时是否返回错误
+	if _, err := NewFromLastID(last, 10); err == nil {
+		t.Error("mismatched node ID expects an error, but got nil")
+	}
+	if _, err := NewFromLastID(-1, 9); err == nil {
+		t.Error("negative id expects an error, but got nil")
+	}
+	if _, err := NewFromLastID(MinIDForTime(time.Now().Add(time.Hour))|9<<nodeIDShift, 9); err == nil {
+		t.Error("id from the future expects an error, but got nil")
+	}
+}
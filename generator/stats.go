@@ -0,0 +1,15 @@
+package generator
+
+// Stats are the lifetime counters of a generator, e.g. to publish through expvar without an Observer
+type Stats struct {
+	Generated uint64 // 已生成的ID数
+	Errors    uint64 // 生成失败次数
+	Overflows uint64 // 时间戳被逻辑推进的次数
+}
+
+// Stats returns a snapshot of the counters of the generator
+func (b *Butterfly) Stats() Stats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.stats
+}
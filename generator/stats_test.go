@@ -0,0 +1,30 @@
+package generator
+
+import "testing"
+
+func TestButterfly_Stats(t *testing.T) {
+	b := NewButterfly(maxTimestamp - 1)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+
+	// 测试生成、进位与失败是否被计数
+	mustGenerate(t, b)
+	if _, err := b.GenerateInBatches(10); err != nil {
+		t.Fatalf("failed to generate ids: %v", err)
+	}
+	stats := b.Stats()
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	b.lowSequence = maxLowSequence
+	if _, err := b.Generate(); err == nil {
+		t.Fatal("exhausted generator expects an error, but got nil")
+	}
+
+	expected := Stats{Generated: 11, Errors: 1, Overflows: 1}
+	if s := b.Stats(); s != expected {
+		t.Errorf("Unexpected stats: %+v, expected %+v", s, expected)
+	}
+	if stats.Errors != 0 {
+		t.Errorf("Unexpected snapshot: %+v, expected it unaffected by later errors", stats)
+	}
+}
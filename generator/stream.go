@@ -0,0 +1,36 @@
+package generator
+
+import "context"
+
+// Stream fills a channel of the given buffer size with ids until ctx is done or the generator is exhausted,
+// then closes it. The error that stopped generation, if any, is sent on the paired channel before it is closed,
+// a done ctx stops it without an error. A negative buffer is treated as 0, an unbuffered channel.
+func (b *Butterfly) Stream(ctx context.Context, buffer int) (<-chan int64, <-chan error) {
+	if buffer < 0 {
+		buffer = 0
+	}
+	ids := make(chan int64, buffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(ids)
+
+		for ctx.Err() == nil {
+			id, err := b.generate(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					errs <- err
+				}
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ids <- id:
+			}
+		}
+	}()
+	return ids, errs
+}
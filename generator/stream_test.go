@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestButterfly_Stream(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	b := NewButterfly(time.Now().UnixMilli())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 测试流中的ID是否递增
+	ids, errs := b.Stream(ctx, 16)
+	lastID := <-ids
+	for i := 0; i < 1000; i++ {
+		id := <-ids
+		if id <= lastID {
+			t.Errorf("ID not incrementing: %d, %d", id, lastID)
+		}
+		lastID = id
+	}
+
+	// 测试取消后通道是否关闭且协程退出
+	cancel()
+	for range ids {
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("cancelled stream expects no error, but got %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if runtime.NumGoroutine() > goroutines {
+		t.Errorf("goroutine leaked: %d running, %d before", runtime.NumGoroutine(), goroutines)
+	}
+
+	// 测试已取消时是否不再生成ID
+	before := b.Stats().Generated
+	ids, errs = b.Stream(ctx, 4)
+	for range ids {
+		t.Error("cancelled stream expects no id")
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("cancelled stream expects no error, but got %v", err)
+	}
+	if generated := b.Stats().Generated; generated != before {
+		t.Errorf("Unexpected generated: %d, expected %d", generated, before)
+	}
+
+	// 测试负数缓冲区是否按无缓冲处理而非panic
+	ctx, cancel = context.WithCancel(context.Background())
+	ids, _ = b.Stream(ctx, -1)
+	if cap(ids) != 0 {
+		t.Errorf("Unexpected buffer: %d, expected %d", cap(ids), 0)
+	}
+	<-ids
+	cancel()
+	for range ids {
+	}
+
+	// 测试耗尽时是否通过错误通道返回错误
+	b = NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	ids, errs = b.Stream(context.Background(), 0)
+	for range ids {
+	}
+	if err := <-errs; err == nil {
+		t.Error("exhausted stream expects an error, but got nil")
+	}
+}
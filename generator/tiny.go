@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+)
+
+// NewTiny constructs a clock-based generator with TinyConfig, whose ids fit into an int32 for constrained consumers.
+// epoch is in seconds and should be recent, as the ids last only about 24 days past it, see TinyConfig for the limits.
+func NewTiny(epoch, nodeID int64) (*Butterfly, error) {
+	return New(WithConfig(TinyConfig()), WithClockMode(), WithEpoch(epoch), WithNodeID(nodeID))
+}
+
+// GenerateInt32 is like GenerateWithClock, but returns the id as an int32.
+// It fails unless the generator was configured with a tiny layout.
+func (b *Butterfly) GenerateInt32() (int32, error) {
+	if maxID := b.layout.maxID(); maxID > math.MaxInt32 {
+		return 0, fmt.Errorf("the max id %d of the layout does not fit into an int32, use a tiny layout", maxID)
+	}
+	id, err := b.GenerateWithClock()
+	if err != nil {
+		return 0, err
+	}
+	return int32(id), nil
+}
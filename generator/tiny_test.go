@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTiny(t *testing.T) {
+	epoch := time.Now().Add(-time.Hour).Unix()
+	b, err := NewTiny(epoch, 11)
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试生成的ID是否为递增的int32，且解析出秒级时间戳与节点ID
+	var last int32 = -1
+	for i := 0; i < 200; i++ {
+		id, err := b.GenerateInt32()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if id <= last {
+			t.Errorf("ID not incrementing: %d, %d", id, last)
+		}
+		last = id
+
+		c := b.Decompose(int64(id))
+		if c.NodeID != 11 {
+			t.Errorf("Unexpected node ID: %d, expected %d on %d times loop", c.NodeID, 11, i)
+		}
+		if d := time.Since(c.Time); d < -5*time.Second || d > 5*time.Second {
+			t.Errorf("Unexpected time: %v, expected about now on %d times loop", c.Time, i)
+		}
+	}
+	if l := b.Layout(); l.Resolution != time.Second || l.TimestampBits+l.HighSequenceBits+l.NodeBits+l.LowSequenceBits != 31 {
+		t.Errorf("Unexpected layout: %+v", l)
+	}
+
+	// 测试非法配置以及非tiny布局的GenerateInt32是否返回错误
+	if _, err := NewTiny(epoch, 16); err == nil {
+		t.Error("node ID 16 expects an error, but got nil")
+	}
+	cfg := TinyConfig()
+	cfg.TimestampBits++
+	if _, err := NewWithConfig(cfg); err == nil {
+		t.Error("32 bit tiny layout expects an error, but got nil")
+	}
+	cfg = DefaultConfig()
+	cfg.Resolution = time.Second
+	if _, err := NewWithConfig(cfg); err == nil {
+		t.Error("second resolution without tiny expects an error, but got nil")
+	}
+	if _, err := NewButterfly(time.Now().UnixMilli()).GenerateInt32(); err == nil {
+		t.Error("default layout expects an error, but got nil")
+	}
+}
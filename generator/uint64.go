@@ -0,0 +1,22 @@
+package generator
+
+import "fmt"
+
+// GenerateUint64 generates an id as an unsigned value for consumers storing ids in unsigned columns.
+// The layout only takes 63 bits and compose masks every field, so the sign bit of an int64 id is never set
+// and the unsigned value is always numerically equal to the signed one: GenerateUint64 == uint64(Generate).
+func (b *Butterfly) GenerateUint64() (uint64, error) {
+	id, err := b.Generate()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// ParseUint64 is the unsigned counterpart of ParseID, it rejects values with the top bit set as no generator emits them
+func ParseUint64(id uint64) (timestamp, highSequence, nodeID, lowSequence int64, err error) {
+	if id > maxState {
+		return 0, 0, 0, 0, fmt.Errorf("invalid id %d: the top bit is never set in a generated id", id)
+	}
+	return ParseID(int64(id))
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestButterfly_GenerateUint64(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+	signed := NewButterfly(initTimestamp)
+
+	// 测试无符号ID是否与有符号ID一致
+	for i := 0; i < 1000; i++ {
+		id, err := b.GenerateUint64()
+		if err != nil {
+			t.Fatalf("failed to generate id: %v", err)
+		}
+		if expected := mustGenerate(t, signed); id != uint64(expected) {
+			t.Errorf("Unexpected id: %d, expected %d", id, expected)
+		}
+
+		timestamp, _, _, _, err := ParseUint64(id)
+		if err != nil {
+			t.Fatalf("failed to parse id %d: %v", id, err)
+		}
+		if timestamp != initTimestamp {
+			t.Errorf("Unexpected timestamp: %d, expected %d", timestamp, initTimestamp)
+		}
+	}
+
+	// 测试最大字段值是否不会设置符号位
+	b = NewButterfly(maxTimestamp)
+	b.highSequence = maxHighSequence
+	b.nodeID = maxNodeID
+	id, err := b.GenerateUint64()
+	if err != nil {
+		t.Fatalf("failed to generate id: %v", err)
+	}
+	if id != math.MaxInt64 {
+		t.Errorf("Unexpected id: %d, expected %d", id, int64(math.MaxInt64))
+	}
+
+	if _, _, _, _, err := ParseUint64(math.MaxInt64 + 1); err == nil {
+		t.Error("id with the top bit set expects an error, but got nil")
+	}
+}
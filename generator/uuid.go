@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// GenerateUUID generates an id and embeds it into a version 8 UUID, whose remaining bits are random.
+// The 63 bits of the id are spread over the UUID around its version and variant bits:
+//
+//	bytes 0-5  bits 62-15 of the id
+//	byte  6    version 8 in the high nibble, bits 14-11 of the id in the low nibble
+//	byte  7    bits 10-3 of the id
+//	byte  8    variant 10 in the 2 high bits, bits 2-0 of the id, then 3 random bits
+//	bytes 9-15 random
+//
+// As the timestamp leads the id, UUIDs of one generator sort by creation time in their high bytes.
+func (b *Butterfly) GenerateUUID() (string, error) {
+	id, err := b.Generate()
+	if err != nil {
+		return "", err
+	}
+
+	var u [16]byte
+	if _, err := rand.Read(u[8:]); err != nil {
+		return "", fmt.Errorf("failed to read the random part of the uuid: %w", err)
+	}
+	value := uint64(id)
+	for i := 0; i < 6; i++ {
+		u[i] = byte(value >> (15 + 8*(5-i)))
+	}
+	u[6] = 0x80 | byte(value>>11)&0x0f
+	u[7] = byte(value >> 3)
+	u[8] = 0x80 | byte(value&0x07)<<3 | u[8]&0x07
+
+	return formatUUID(u), nil
+}
+
+// ParseUUID recovers the id embedded by GenerateUUID, its timestamp is available through ExtractTime
+func ParseUUID(s string) (int64, error) {
+	u, err := parseUUID(s)
+	if err != nil {
+		return 0, err
+	}
+	if u[6]>>4 != 0x8 || u[8]>>6 != 0x2 {
+		return 0, fmt.Errorf("invalid uuid %q: not a version 8 uuid generated by GenerateUUID", s)
+	}
+
+	var value uint64
+	for i := 0; i < 6; i++ {
+		value |= uint64(u[i]) << (15 + 8*(5-i))
+	}
+	value |= uint64(u[6]&0x0f) << 11
+	value |= uint64(u[7]) << 3
+	value |= uint64(u[8]>>3) & 0x07
+	return int64(value), nil
+}
+
+func formatUUID(u [16]byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:36], u[10:16])
+	return string(buf)
+}
+
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid uuid %q: not in the canonical 8-4-4-4-12 form", s)
+	}
+
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return u, fmt.Errorf("invalid uuid %q: %w", s, err)
+	}
+	return u, nil
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestButterfly_GenerateUUID(t *testing.T) {
+	initTimestamp := time.Now().UnixMilli()
+	b := NewButterfly(initTimestamp)
+	expected := NewButterfly(initTimestamp)
+
+	// 测试解析UUID是否得到原ID
+	var uuids []string
+	for i := 0; i < 1000; i++ {
+		u, err := b.GenerateUUID()
+		if err != nil {
+			t.Fatalf("failed to generate uuid: %v", err)
+		}
+		uuids = append(uuids, u)
+
+		id, err := ParseUUID(u)
+		if err != nil {
+			t.Fatalf("failed to parse uuid %q: %v", u, err)
+		}
+		if e := mustGenerate(t, expected); id != e {
+			t.Errorf("Unexpected id: %d, expected %d from %q", id, e, u)
+		}
+		if u[14] != '8' || (u[19] != '8' && u[19] != '9' && u[19] != 'a' && u[19] != 'b') {
+			t.Errorf("Unexpected version or variant of %q", u)
+		}
+	}
+	if !sort.StringsAreSorted(uuids) {
+		t.Error("uuids expect to sort by creation order")
+	}
+	if id, _ := ParseUUID(uuids[0]); ExtractTime(id).UnixMilli() != initTimestamp {
+		t.Errorf("Unexpected creation time: %v, expected %v", ExtractTime(id), time.UnixMilli(initTimestamp))
+	}
+
+	// 测试非法输入是否被拒绝
+	for _, s := range []string{"", "not-a-uuid", "123e4567-e89b-42d3-a456-426614174000", "zzzzzzzz-zzzz-8zzz-8zzz-zzzzzzzzzzzz"} {
+		if _, err := ParseUUID(s); err == nil {
+			t.Errorf("parsing %q expects an error, but got nil", s)
+		}
+	}
+}
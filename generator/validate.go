@@ -0,0 +1,86 @@
+package generator
+
+import "fmt"
+
+// FieldError reports which field of an id is out of its legal range
+type FieldError struct {
+	ID    int64  // 出错的ID
+	Field string // 越界的字段名
+	Value int64  // 字段值
+	Max   int64  // 字段最大值
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid id %d: the %s %d exceeds the max %d", e.ID, e.Field, e.Value, e.Max)
+}
+
+// Validate checks that every field packed into an id of the default layout is within its max, returning a *FieldError if not.
+// As the lower fields are bounded by their bit width, a failure means the bits above the timestamp, i.e. the sign bit, are set.
+func Validate(id int64) error {
+	return defaultLayout.validate(id)
+}
+
+func (l layout) validate(id int64) error {
+	if err := l.checkMarker(id); err != nil {
+		return err
+	}
+	value := uint64(id &^ l.marker)
+	fields := []struct {
+		name  string
+		value uint64
+		max   int64
+	}{
+		{name: "timestamp", value: value >> l.timeShift, max: l.maxTimestamp},
+		{name: "high sequence", value: (value >> l.highSequenceShift) & uint64(l.maxHighSequence), max: l.maxHighSequence},
+		{name: "node ID", value: (value >> l.nodeIDShift) & uint64(l.maxNodeID), max: l.maxNodeID},
+		{name: "low sequence", value: (value >> l.lowSequenceShift) & uint64(l.maxLowSequence), max: l.maxLowSequence},
+	}
+	for _, f := range fields {
+		if f.value > uint64(f.max) {
+			return &FieldError{ID: id, Field: f.name, Value: int64(f.value), Max: f.max}
+		}
+	}
+	return nil
+}
+
+// Validate checks the invariants of the generator: the node ID, timestamp and sequences are within the layout,
+// the layout matches the config it was built from, and the current state packs into a non-negative id that parses back unchanged.
+// New calls it, so misconfigurations surface at construction instead of on the first Generate.
+func (b *Butterfly) Validate() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	l := b.layout
+	if err := l.validateNodeID(b.nodeID); err != nil {
+		return err
+	}
+	if b.timestamp < b.epoch {
+		return fmt.Errorf("the timestamp %d is earlier than the epoch %d", b.timestamp, b.epoch)
+	}
+	if b.timestamp-b.epoch > l.maxTimestamp {
+		return fmt.Errorf("%w: the timestamp %d exceeds the max timestamp %d since the epoch %d", ErrTimestampOverflow, b.timestamp, l.maxTimestamp, b.epoch)
+	}
+	if b.highSequence < 0 || b.highSequence > l.maxHighSequence || b.lowSequence < 0 || b.lowSequence > l.maxLowSequence {
+		return fmt.Errorf("invalid generator: the sequences %d/%d are out of range [0, %d]/[0, %d]", b.highSequence, b.lowSequence, l.maxHighSequence, l.maxLowSequence)
+	}
+
+	// the layout is rebuilt from the config instead of checked against itself, so a corrupted shift, width or marker shows
+	cfg := b.config
+	if cfg == (Config{}) {
+		cfg = DefaultConfig()
+	}
+	expected, err := cfg.layout()
+	if err != nil {
+		return fmt.Errorf("invalid generator: %w", err)
+	}
+	if l != expected {
+		return fmt.Errorf("invalid generator: the layout %+v does not match the layout %+v of the config %+v", l.export(), expected.export(), cfg)
+	}
+
+	id := b.compose()
+	timestamp, highSequence, nodeID, lowSequence := l.fields(id, b.epoch)
+	if id < 0 || timestamp != b.timestamp || highSequence != b.highSequence || nodeID != b.nodeID || lowSequence != b.lowSequence {
+		return fmt.Errorf("invalid generator: the state packs into %d, which does not parse back", id)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	b := NewButterfly(time.Now().UnixMilli())
+	for i := 0; i < 1000; i++ {
+		if id := mustGenerate(t, b); Validate(id) != nil {
+			t.Errorf("generated id %d expects no error, but got %v", id, Validate(id))
+		}
+	}
+	if err := Validate(math.MaxInt64); err != nil {
+		t.Errorf("max id expects no error, but got %v", err)
+	}
+
+	// 测试越界字段是否被识别
+	var fieldErr *FieldError
+	if err := Validate(-1); !errors.As(err, &fieldErr) {
+		t.Fatalf("negative id expects a field error, but got %v", err)
+	}
+	if fieldErr.Field != "timestamp" || fieldErr.Value != 1<<42-1 || fieldErr.Max != maxTimestamp {
+		t.Errorf("Unexpected field error: %+v", fieldErr)
+	}
+}
+
+func TestButterfly_Validate(t *testing.T) {
+	b, err := New()
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if err := b.Validate(); err != nil {
+		t.Errorf("fresh generator expects no error, but got %v", err)
+	}
+
+	// 测试损坏的配置与状态是否被发现
+	corruptions := map[string]func(b *Butterfly){
+		"node ID":   func(b *Butterfly) { b.nodeID = maxNodeID + 1 },
+		"epoch":     func(b *Butterfly) { b.epoch = b.timestamp + 1 },
+		"timestamp": func(b *Butterfly) { b.timestamp = maxTimestamp + 1 },
+		"sequence":  func(b *Butterfly) { b.lowSequence = -1 },
+		"shift":     func(b *Butterfly) { b.layout.timeShift++ },
+		"width":     func(b *Butterfly) { b.layout.maxNodeID = 1<<14 - 1 },
+		"marker":    func(b *Butterfly) { b.layout.marker = 1 << 40 },
+		"swap":      func(b *Butterfly) { b.layout.nodeIDShift, b.layout.lowSequenceShift = 0, nodeBits },
+		"unit":      func(b *Butterfly) { b.layout.unit = time.Microsecond },
+	}
+	for name, corrupt := range corruptions {
+		t.Run(name, func(t *testing.T) {
+			c := b.Clone()
+			corrupt(c)
+			if err := c.Validate(); err == nil {
+				t.Errorf("corrupted %s expects an error, but got nil", name)
+			}
+		})
+	}
+
+	// 测试位移是否按配置独立推导，而非与布局自身比较
+	cfg := DefaultConfig()
+	cfg.SequenceAboveNode = true
+	d, err := New(WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+	if err := d.Validate(); err != nil {
+		t.Errorf("fresh generator expects no error, but got %v", err)
+	}
+	d.layout.nodeIDShift, d.layout.lowSequenceShift = 1, 0
+	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Unexpected error: %v, expected the shifts to mismatch the config", err)
+	}
+}
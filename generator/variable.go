@@ -1,9 +1,14 @@
 package generator
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 var (
 	instanceList []*ButterflyList
+	// instanceMutex guards instanceList, which lists are appended to while checkUnusedIDListCount reads it
+	instanceMutex sync.Mutex
 	// period defines the  execution period of the checkUnusedIDListCount in the gorountine
 	period = 300 * time.Second
 )
@@ -14,15 +19,32 @@ func init() {
 
 func checkUnusedIDListCount() {
 	for {
-		for _, instance := range instanceList {
-			instance.mutex.Lock()
-			defer instance.mutex.Unlock()
+		refillUnusedIDLists()
+		time.Sleep(period)
+	}
+}
 
-			if instance.AtLeastCount < len(instance.UnusedIDList) {
-				instance.UnusedIDList = append(instance.UnusedIDList, instance.generator.GenerateInBatches(instance.IncreaseCount)...)
-			}
+// refillUnusedIDLists appends IncreaseCount ids to every list holding fewer than AtLeastCount,
+// a failed refill appends nothing and is logged through the logger of the generator if it is a Butterfly
+func refillUnusedIDLists() {
+	instanceMutex.Lock()
+	instances := append([]*ButterflyList(nil), instanceList...)
+	instanceMutex.Unlock()
 
+	for _, instance := range instances {
+		instance.mutex.Lock()
+		if instance.AtLeastCount > len(instance.UnusedIDList) {
+			idList, err := instance.generator.GenerateInBatches(instance.IncreaseCount)
+			if err != nil {
+				if b, ok := instance.generator.(*Butterfly); ok {
+					b.mutex.Lock()
+					b.logf("failed to refill the unused id list: %v", err)
+					b.mutex.Unlock()
+				}
+			} else {
+				instance.UnusedIDList = append(instance.UnusedIDList, idList...)
+			}
 		}
-		time.Sleep(period)
+		instance.mutex.Unlock()
 	}
 }
@@ -0,0 +1,41 @@
+package generator
+
+import "time"
+
+// MinIDForTime returns the smallest id of the default layout and epoch whose timestamp is t, with the high sequence,
+// node ID and low sequence all zero. Together with MaxIDForTime it turns "created between t1 and t2" into
+// a numeric BETWEEN MinIDForTime(t1) AND MaxIDForTime(t2). Times out of the layout are clamped to its range.
+func MinIDForTime(t time.Time) int64 {
+	return defaultLayout.minIDForTime(t, 0)
+}
+
+// MinIDForTime is like the package level MinIDForTime but honors the layout and epoch of the generator
+func (b *Butterfly) MinIDForTime(t time.Time) int64 {
+	return b.layout.minIDForTime(t, b.epoch)
+}
+
+// MaxIDForTime returns the largest id of the default layout and epoch whose timestamp is t, with every bit
+// below the timestamp set, which covers all node IDs as the node bits sit between the sequences
+func MaxIDForTime(t time.Time) int64 {
+	return defaultLayout.maxIDForTime(t, 0)
+}
+
+// MaxIDForTime is like the package level MaxIDForTime but honors the layout and epoch of the generator
+func (b *Butterfly) MaxIDForTime(t time.Time) int64 {
+	return b.layout.maxIDForTime(t, b.epoch)
+}
+
+func (l layout) minIDForTime(t time.Time, epoch int64) int64 {
+	timestamp := l.fromTime(t) - epoch
+	if timestamp < 0 {
+		timestamp = 0
+	}
+	if timestamp > l.maxTimestamp {
+		timestamp = l.maxTimestamp
+	}
+	return timestamp<<l.timeShift | l.marker
+}
+
+func (l layout) maxIDForTime(t time.Time, epoch int64) int64 {
+	return l.minIDForTime(t, epoch) | (1<<l.timeShift - 1)
+}
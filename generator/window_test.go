@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMinMaxIDForTime(t *testing.T) {
+	const epoch = 1577836800000
+	var now int64 = 1700000000000
+	b, err := New(WithEpoch(epoch), WithClockMode(), WithNodeID(maxNodeID), WithClock(func() int64 { return now }))
+	if err != nil {
+		t.Fatalf("failed to construct the generator: %v", err)
+	}
+
+	// 测试时间窗口内的ID是否都落在边界之间，窗口外的ID都在边界之外
+	var idList []int64
+	for ; now < 1700000000010; now++ {
+		for i := 0; i < 3; i++ {
+			id, err := b.GenerateWithClock()
+			if err != nil {
+				t.Fatalf("failed to generate id: %v", err)
+			}
+			idList = append(idList, id)
+		}
+	}
+	min, max := b.MinIDForTime(time.UnixMilli(1700000000003)), b.MaxIDForTime(time.UnixMilli(1700000000006))
+	for _, id := range idList {
+		inWindow := b.Decompose(id).Timestamp >= 1700000000003 && b.Decompose(id).Timestamp <= 1700000000006
+		if inWindow != (id >= min && id <= max) {
+			t.Errorf("Unexpected bounds [%d, %d] of id %d at %d", min, max, id, b.Decompose(id).Timestamp)
+		}
+	}
+
+	// 测试边界ID本身是否解析为给定的时间，以及超出布局的时间是否被截断
+	tm := time.UnixMilli(1714521600000)
+	for _, id := range []int64{MinIDForTime(tm), MaxIDForTime(tm)} {
+		if c := Decompose(id); c.Timestamp != 1714521600000 {
+			t.Errorf("Unexpected timestamp: %d, expected %d", c.Timestamp, int64(1714521600000))
+		}
+	}
+	if c := Decompose(MaxIDForTime(tm)); c.HighSequence != maxHighSequence || c.NodeID != maxNodeID || c.LowSequence != maxLowSequence {
+		t.Errorf("Unexpected fields: %+v, expected every field at its max", c)
+	}
+	if id := MinIDForTime(time.UnixMilli(-1)); id != 0 {
+		t.Errorf("Unexpected id: %d, expected %d", id, 0)
+	}
+	if id := MaxIDForTime(time.UnixMilli(maxTimestamp + 1)); id != defaultLayout.maxID() {
+		t.Errorf("Unexpected id: %d, expected %d", id, defaultLayout.maxID())
+	}
+}